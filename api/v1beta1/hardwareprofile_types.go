@@ -0,0 +1,239 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE! THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required. Any new fields you add
+// must have json tags for the fields to be serialized.
+
+// Architecture is a CPU architecture using the naming of the Go toolchain.
+// +kubebuilder:validation:Enum=amd64;arm64
+type Architecture string
+
+const (
+	// ArchitectureAMD64 is the 64-bit x86 architecture.
+	ArchitectureAMD64 Architecture = "amd64"
+	// ArchitectureARM64 is the 64-bit ARM architecture.
+	ArchitectureARM64 Architecture = "arm64"
+)
+
+// Firmware is the boot firmware of a machine.
+// +kubebuilder:validation:Enum=UEFI;BIOS;UBoot
+type Firmware string
+
+const (
+	// FirmwareUEFI is the Unified Extensible Firmware Interface.
+	FirmwareUEFI Firmware = "UEFI"
+	// FirmwareBIOS is the legacy Basic Input/Output System.
+	FirmwareBIOS Firmware = "BIOS"
+	// FirmwareUBoot is the Das U-Boot bootloader commonly used on SBCs.
+	FirmwareUBoot Firmware = "UBoot"
+)
+
+// DiskInterface is the interface used to attach a disk.
+// +kubebuilder:validation:Enum=NVMe;SATA;SAS;eMMC;SD;USB
+type DiskInterface string
+
+const (
+	// DiskInterfaceNVMe is a PCIe attached NVMe disk.
+	DiskInterfaceNVMe DiskInterface = "NVMe"
+	// DiskInterfaceSATA is a SATA attached disk.
+	DiskInterfaceSATA DiskInterface = "SATA"
+	// DiskInterfaceSAS is a SAS attached disk.
+	DiskInterfaceSAS DiskInterface = "SAS"
+	// DiskInterfaceEMMC is an onboard eMMC module.
+	DiskInterfaceEMMC DiskInterface = "eMMC"
+	// DiskInterfaceSD is an SD card.
+	DiskInterfaceSD DiskInterface = "SD"
+	// DiskInterfaceUSB is a USB attached disk.
+	DiskInterfaceUSB DiskInterface = "USB"
+)
+
+// HardwareProfileReference references a HardwareProfile by name.
+type HardwareProfileReference struct {
+	// Name is the name of the referenced HardwareProfile.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// HardwareCPU describes the processor of a machine.
+type HardwareCPU struct {
+	// Architecture is the CPU architecture.
+	// +kubebuilder:validation:Required
+	Architecture Architecture `json:"architecture"`
+	// Cores is the number of physical CPU cores.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Cores int32 `json:"cores,omitempty"`
+}
+
+// HardwareNIC describes an onboard network interface controller.
+type HardwareNIC struct {
+	// Name is the name of the port, e.g. as printed on the chassis.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// Speed is the maximum link speed of the port in Mbit/s.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Speed int32 `json:"speed,omitempty"`
+}
+
+// HardwareDiskSlot describes a slot that can hold a disk.
+type HardwareDiskSlot struct {
+	// Name is the name of the slot.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// Interface is the interface of the slot.
+	// +kubebuilder:validation:Required
+	Interface DiskInterface `json:"interface"`
+}
+
+// TalosOverlay is a Talos overlay that adds board specific support,
+// such as bootloaders and device trees for single board computers.
+type TalosOverlay struct {
+	// Name is the name of the overlay, e.g. "nanopi-r5s".
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// Image is the container image of the overlay, e.g. "siderolabs/sbc-rockchip".
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Image string `json:"image"`
+}
+
+// HardwareTalos describes how Talos is installed on the hardware.
+type HardwareTalos struct {
+	// Installer is the installer image without tag, e.g. one generated by the
+	// Talos Image Factory. Defaults to "ghcr.io/siderolabs/installer" or, if
+	// an overlay is set, to the installer of the Image Factory for the overlay.
+	// +optional
+	Installer string `json:"installer,omitempty"`
+	// Overlay is the overlay required to boot the hardware.
+	// +optional
+	Overlay *TalosOverlay `json:"overlay,omitempty"`
+	// KernelArgs are additional kernel arguments required by the hardware.
+	// +optional
+	KernelArgs []string `json:"kernelArgs,omitempty"`
+}
+
+// HardwareProfileSpec defines the desired state of a HardwareProfile.
+type HardwareProfileSpec struct {
+	// Vendor is the manufacturer of the machine.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Vendor string `json:"vendor,omitempty"`
+	// Model is the model of the machine.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Model string `json:"model,omitempty"`
+	// CPU describes the processor of the machine.
+	// +optional
+	CPU *HardwareCPU `json:"cpu,omitempty"`
+	// Memory is the amount of installed memory.
+	// +optional
+	Memory *resource.Quantity `json:"memory,omitempty"`
+	// NICs describes the onboard network interface controllers.
+	// +optional
+	NICs []HardwareNIC `json:"nics,omitempty"`
+	// DiskSlots describes the slots that can hold disks.
+	// +optional
+	DiskSlots []HardwareDiskSlot `json:"diskSlots,omitempty"`
+	// Firmware is the boot firmware of the machine.
+	// +optional
+	Firmware Firmware `json:"firmware,omitempty"`
+	// Talos describes how Talos is installed on the machine.
+	// +optional
+	Talos *HardwareTalos `json:"talos,omitempty"`
+}
+
+// Merge fills all fields that are not set with the values of the given
+// profile. Fields that are already set take precedence over the profile.
+func (in *HardwareProfileSpec) Merge(profile *HardwareProfileSpec) {
+	if profile == nil {
+		return
+	}
+
+	merged := profile.DeepCopy()
+
+	if in.Vendor == "" {
+		in.Vendor = merged.Vendor
+	}
+	if in.Model == "" {
+		in.Model = merged.Model
+	}
+	if in.CPU == nil {
+		in.CPU = merged.CPU
+	}
+	if in.Memory == nil {
+		in.Memory = merged.Memory
+	}
+	if in.NICs == nil {
+		in.NICs = merged.NICs
+	}
+	if in.DiskSlots == nil {
+		in.DiskSlots = merged.DiskSlots
+	}
+	if in.Firmware == "" {
+		in.Firmware = merged.Firmware
+	}
+	if in.Talos == nil {
+		in.Talos = merged.Talos
+	}
+}
+
+// HardwareProfileStatus defines the observed state of a HardwareProfile.
+type HardwareProfileStatus struct {
+	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Vendor",type=string,JSONPath=`.spec.vendor`
+// +kubebuilder:printcolumn:name="Model",type=string,JSONPath=`.spec.model`
+// +kubebuilder:printcolumn:name="Architecture",type=string,JSONPath=`.spec.cpu.architecture`
+
+// HardwareProfile describes a hardware model that is shared by many Machines.
+type HardwareProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:XValidation:rule="has(self.vendor) && has(self.model)",message="vendor and model are required"
+	Spec   HardwareProfileSpec   `json:"spec,omitempty"`
+	Status HardwareProfileStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HardwareProfileList contains a list of HardwareProfile
+type HardwareProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HardwareProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HardwareProfile{}, &HardwareProfileList{})
+}
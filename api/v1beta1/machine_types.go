@@ -21,8 +21,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"net/netip"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -32,6 +34,8 @@ import (
 
 // MAC is a MAC address that can be marshaled
 // to and unmarshaled from YAML.
+// +kubebuilder:validation:Type=string
+// +kubebuilder:validation:Format=mac
 type MAC net.HardwareAddr
 
 // String returns the string representation of a MAC address.
@@ -49,6 +53,17 @@ func (m MAC) String() string {
 	return builder.String()
 }
 
+// ParseMAC parses a MAC address in any of the formats supported
+// by net.ParseMAC, e.g. "32:de:fa:97:71:4f" or "32-DE-FA-97-71-4F".
+func ParseMAC(mac string) (MAC, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse MAC address: %w", err)
+	}
+
+	return MAC(hw), nil
+}
+
 // UnmarshalYAML unmarshals a MAC address from a string.
 func (m *MAC) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var mac string
@@ -57,12 +72,12 @@ func (m *MAC) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return fmt.Errorf("failed to unmarshal MAC address: %w", err)
 	}
 
-	hw, err := net.ParseMAC(mac)
+	parsed, err := ParseMAC(mac)
 	if err != nil {
-		return fmt.Errorf("failed to parse MAC address: %w", err)
+		return err
 	}
 
-	*m = MAC(hw)
+	*m = parsed
 
 	return nil
 }
@@ -80,12 +95,12 @@ func (m *MAC) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("failed to unmarshal MAC address: %w", err)
 	}
 
-	hw, err := net.ParseMAC(mac)
+	parsed, err := ParseMAC(mac)
 	if err != nil {
-		return fmt.Errorf("failed to parse MAC address: %w", err)
+		return err
 	}
 
-	*m = MAC(hw)
+	*m = parsed
 
 	return nil
 }
@@ -95,27 +110,279 @@ func (m MAC) MarshalJSON() ([]byte, error) {
 	return []byte(fmt.Sprintf(`"%s"`, m.String())), nil
 }
 
+// LinkConfig configures the addresses of a network link,
+// i.e. an interface, a VLAN, a bond or a bridge.
+type LinkConfig struct {
+	// Addresses are the static IPv4 and IPv6 addresses of the link in CIDR
+	// notation, e.g. "10.0.0.10/24". Links without static addresses are
+	// configured via DHCP.
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:MaxLength=64
+	// +kubebuilder:validation:XValidation:rule="self.all(a, isCIDR(a))",message="addresses must be in CIDR notation"
+	// +optional
+	Addresses []string `json:"addresses,omitempty"`
+	// Gateway is the address of the default gateway that is reachable
+	// via the link, e.g. "10.0.0.1". It must be part of the network of
+	// one of the addresses.
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:XValidation:rule="isIP(self)",message="gateway must be an IP address"
+	// +optional
+	Gateway string `json:"gateway,omitempty"`
+	// MTU is the maximum transmission unit of the link.
+	// +kubebuilder:validation:Minimum=68
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	MTU int32 `json:"mtu,omitempty"`
+}
+
+// Prefixes returns the static addresses of the link. Addresses
+// that can not be parsed are skipped, as they are rejected by
+// the validation anyway.
+func (c *LinkConfig) Prefixes() []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(c.Addresses))
+	for _, address := range c.Addresses {
+		if prefix, err := netip.ParsePrefix(address); err == nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	return prefixes
+}
+
+// VLAN describes a tagged VLAN on top of a network link.
+type VLAN struct {
+	// ID is the VLAN ID.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	// +kubebuilder:validation:Required
+	ID int32 `json:"id"`
+	// LinkConfig configures the addresses of the VLAN.
+	LinkConfig `json:",inline"`
+}
+
 // Interface describes a network interface of a Machine.
 type Interface struct {
 	// MAC is the MAC address of the interface.
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Format=mac
 	MAC MAC `json:"mac"`
+	// Name is the name of the interface in the operating system, e.g.
+	// "enp1s0". It is required to add the interface to a bond or a bridge.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,14}$`
+	// +optional
+	Name string `json:"name,omitempty"`
+	// LinkConfig configures the addresses of the interface.
+	LinkConfig `json:",inline"`
+	// VLANs are the VLANs on top of the interface.
+	// +kubebuilder:validation:MaxItems=16
+	// +listType=map
+	// +listMapKey=id
+	// +optional
+	VLANs []VLAN `json:"vlans,omitempty"`
+}
+
+// BondMode is the mode of a bond.
+// +kubebuilder:validation:Enum=balance-rr;active-backup;balance-xor;broadcast;"802.3ad";balance-tlb;balance-alb
+type BondMode string
+
+const (
+	// BondModeActiveBackup only uses one member at a time.
+	BondModeActiveBackup BondMode = "active-backup"
+	// BondMode8023AD uses link aggregation via LACP.
+	BondMode8023AD BondMode = "802.3ad"
+)
+
+// Bond aggregates network interfaces of a Machine to a single link.
+type Bond struct {
+	// Name is the name of the bond in the operating system, e.g. "bond0".
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,14}$`
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// Interfaces are the names of the interfaces that are members of the bond.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:MaxLength=15
+	// +kubebuilder:validation:Required
+	Interfaces []string `json:"interfaces"`
+	// Mode is the mode of the bond. Defaults to active-backup.
+	// +kubebuilder:default=active-backup
+	// +optional
+	Mode BondMode `json:"mode,omitempty"`
+	// LinkConfig configures the addresses of the bond.
+	LinkConfig `json:",inline"`
+	// VLANs are the VLANs on top of the bond.
+	// +kubebuilder:validation:MaxItems=16
+	// +listType=map
+	// +listMapKey=id
+	// +optional
+	VLANs []VLAN `json:"vlans,omitempty"`
+}
+
+// Bridge connects network interfaces or bonds of a Machine.
+type Bridge struct {
+	// Name is the name of the bridge in the operating system, e.g. "br0".
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,14}$`
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// Interfaces are the names of the interfaces
+	// or bonds that are members of the bridge.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:MaxLength=15
+	// +kubebuilder:validation:Required
+	Interfaces []string `json:"interfaces"`
+	// STP enables the spanning tree protocol on the bridge.
+	// +optional
+	STP bool `json:"stp,omitempty"`
+	// LinkConfig configures the addresses of the bridge.
+	LinkConfig `json:",inline"`
+	// VLANs are the VLANs on top of the bridge.
+	// +kubebuilder:validation:MaxItems=16
+	// +listType=map
+	// +listMapKey=id
+	// +optional
+	VLANs []VLAN `json:"vlans,omitempty"`
 }
 
 // MachineSpecHardware defines the hardware configuration of a Machine.
+// The fields of the referenced HardwareProfile are used as defaults for
+// all fields that are not set on the Machine itself.
+// +kubebuilder:validation:XValidation:rule="has(self.profileRef) || (has(self.vendor) && has(self.model))",message="vendor and model are required if no profileRef is set"
 type MachineSpecHardware struct {
-	// Vendor is the manufacturer of the machine.
+	// ProfileRef references the HardwareProfile of the machine.
+	// +optional
+	ProfileRef *HardwareProfileReference `json:"profileRef,omitempty"`
+	// HardwareProfileSpec allows to override fields of the HardwareProfile.
+	HardwareProfileSpec `json:",inline"`
+}
+
+// DiskType is the type of a disk.
+// +kubebuilder:validation:Enum=ssd;hdd;nvme;sd
+type DiskType string
+
+const (
+	// DiskTypeSSD is a solid state drive that is not attached via NVMe.
+	DiskTypeSSD DiskType = "ssd"
+	// DiskTypeHDD is a rotational hard disk drive.
+	DiskTypeHDD DiskType = "hdd"
+	// DiskTypeNVMe is a solid state drive that is attached via NVMe.
+	DiskTypeNVMe DiskType = "nvme"
+	// DiskTypeSD is an SD card or an eMMC module.
+	DiskTypeSD DiskType = "sd"
+)
+
+// DiskRole is the purpose of a disk.
+// +kubebuilder:validation:Enum=install;data;ephemeral
+type DiskRole string
+
+const (
+	// DiskRoleInstall is the disk that the operating system is installed to.
+	DiskRoleInstall DiskRole = "install"
+	// DiskRoleData is a disk for persistent data, e.g. of storage providers.
+	DiskRoleData DiskRole = "data"
+	// DiskRoleEphemeral is a disk for ephemeral data, e.g. container images.
+	DiskRoleEphemeral DiskRole = "ephemeral"
+)
+
+// Disk describes a disk of a Machine. A disk is identified by its
+// path in /dev/disk/by-id, which is stable across reboots, or is
+// selected by its serial number, size and type.
+// +kubebuilder:validation:XValidation:rule="has(self.byID) || has(self.serial) || has(self.size) || has(self.type)",message="byID, serial, size or type is required to identify the disk"
+type Disk struct {
+	// ByID is the path of the disk in /dev/disk/by-id,
+	// e.g. "/dev/disk/by-id/nvme-Samsung_SSD_980_1TB_S649NF0R123456".
+	// +kubebuilder:validation:Pattern=`^/dev/disk/by-id/[^/]+$`
+	// +optional
+	ByID string `json:"byID,omitempty"`
+	// Serial is the serial number of the disk.
 	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Serial string `json:"serial,omitempty"`
+	// Size is the nominal capacity of the disk, e.g. "1T".
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+	// Type is the type of the disk.
+	// +optional
+	Type DiskType `json:"type,omitempty"`
+	// Role is the purpose of the disk.
 	// +kubebuilder:validation:Required
-	Vendor string `json:"vendor"`
-	// Model is the model of the machine.
+	Role DiskRole `json:"role"`
+}
+
+// PowerDriver is the driver that controls the power of a Machine.
+// +kubebuilder:validation:Enum=redfish;ipmi;wol;pdu
+type PowerDriver string
+
+const (
+	// PowerDriverRedfish controls the power via the Redfish API of a BMC.
+	PowerDriverRedfish PowerDriver = "redfish"
+	// PowerDriverIPMI controls the power via IPMI over LAN using ipmitool.
+	// It is only supported by labctl, as the manager does not ship ipmitool.
+	PowerDriverIPMI PowerDriver = "ipmi"
+	// PowerDriverWOL powers on the machine via Wake-on-LAN.
+	// It can neither power off the machine nor observe its power state.
+	PowerDriverWOL PowerDriver = "wol"
+	// PowerDriverPDU controls the power via the outlet of a smart PDU
+	// that implements the HTTP API of Tasmota.
+	PowerDriverPDU PowerDriver = "pdu"
+)
+
+// PowerState is the power state of a Machine.
+type PowerState string
+
+const (
+	// PowerStateOn means that the machine is powered on.
+	PowerStateOn PowerState = "On"
+	// PowerStateOff means that the machine is powered off.
+	PowerStateOff PowerState = "Off"
+	// PowerStateUnknown means that the power state could not be observed.
+	PowerStateUnknown PowerState = "Unknown"
+)
+
+// SecretReference references a Secret in the namespace of the referencing resource.
+type SecretReference struct {
+	// Name is the name of the referenced Secret.
 	// +kubebuilder:validation:MinLength=1
 	// +kubebuilder:validation:Required
-	Model string `json:"model"`
+	Name string `json:"name"`
+}
+
+// MachineSpecPower defines how the power of a Machine is controlled.
+// +kubebuilder:validation:XValidation:rule="self.driver == 'wol' || has(self.endpoint)",message="endpoint is required unless the driver is wol"
+// +kubebuilder:validation:XValidation:rule="self.driver == 'pdu' || !has(self.outlet)",message="outlet is only supported by the pdu driver"
+type MachineSpecPower struct {
+	// Driver is the driver that controls the power of the machine.
+	// +kubebuilder:validation:Required
+	Driver PowerDriver `json:"driver"`
+	// Endpoint is the address of the power controller. Depending on the
+	// driver, it is the URL of the BMC, e.g. "https://10.0.0.10", or of a
+	// system of the BMC, e.g. "https://10.0.0.10/redfish/v1/Systems/1",
+	// the address of the BMC with an optional port, e.g. "10.0.0.10:623",
+	// the URL of the PDU, e.g. "http://10.0.0.20", or the broadcast address
+	// that magic packets are sent to, which defaults to "255.255.255.255:9".
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// Outlet is the number of the outlet of the PDU. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Outlet int32 `json:"outlet,omitempty"`
+	// CredentialsSecretRef references a Secret with the keys "username"
+	// and "password", which are used to authenticate with the controller.
+	// +optional
+	CredentialsSecretRef *SecretReference `json:"credentialsSecretRef,omitempty"`
+	// InsecureSkipVerify disables the verification of the TLS
+	// certificate of the controller, which is often self-signed.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// State is the desired power state of the machine. The power
+	// state is only observed, but not changed, if it is not set.
+	// +kubebuilder:validation:Enum=On;Off
+	// +optional
+	State PowerState `json:"state,omitempty"`
 }
 
 // MachineSpec defines the desired state of a Machine.
+// +kubebuilder:validation:XValidation:rule="!has(self.bonds) || self.bonds.all(b, b.interfaces.all(i, self.interfaces.exists(x, has(x.name) && x.name == i)))",message="members of bonds must be named interfaces"
+// +kubebuilder:validation:XValidation:rule="!has(self.bridges) || self.bridges.all(b, b.interfaces.all(i, self.interfaces.exists(x, has(x.name) && x.name == i) || (has(self.bonds) && self.bonds.exists(x, x.name == i))))",message="members of bridges must be named interfaces or bonds"
 type MachineSpec struct {
 	// Hardware is the hardware configuration of the machine.
 	// +kubebuilder:validation:Required
@@ -123,17 +390,159 @@ type MachineSpec struct {
 	// Interfaces describes the network interfaces of the machine.
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=32
 	Interfaces []Interface `json:"interfaces"`
+	// Bonds aggregates the named interfaces of the machine.
+	// +kubebuilder:validation:MaxItems=8
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Bonds []Bond `json:"bonds,omitempty"`
+	// Bridges connects the named interfaces and bonds of the machine.
+	// +kubebuilder:validation:MaxItems=8
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Bridges []Bridge `json:"bridges,omitempty"`
+	// Disks describes the disks of the machine. If disks are
+	// described, exactly one of them must have the role install.
+	// +kubebuilder:validation:MaxItems=64
+	// +kubebuilder:validation:XValidation:rule="self.exists_one(d, d.role == 'install')",message="exactly one disk must have the role install"
+	// +optional
+	Disks []Disk `json:"disks,omitempty"`
+	// Decommissioned marks the machine as retired.
+	// A decommissioned machine is never considered ready.
+	// +optional
+	Decommissioned bool `json:"decommissioned,omitempty"`
+	// Power configures the out-of-band power management of the machine.
+	// +optional
+	Power *MachineSpecPower `json:"power,omitempty"`
+}
+
+// InstallDisk returns the disk with the role install or nil if there is none.
+func (s *MachineSpec) InstallDisk() *Disk {
+	for index := range s.Disks {
+		if s.Disks[index].Role == DiskRoleInstall {
+			return &s.Disks[index]
+		}
+	}
+
+	return nil
+}
+
+// StaticAddresses returns the static addresses of all links of the machine
+// in the order of the interfaces, bonds and bridges and their VLANs.
+func (s *MachineSpec) StaticAddresses() []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0)
+	add := func(config LinkConfig, vlans []VLAN) {
+		prefixes = append(prefixes, config.Prefixes()...)
+		for _, vlan := range vlans {
+			prefixes = append(prefixes, vlan.Prefixes()...)
+		}
+	}
+
+	for _, iface := range s.Interfaces {
+		add(iface.LinkConfig, iface.VLANs)
+	}
+	for _, bond := range s.Bonds {
+		add(bond.LinkConfig, bond.VLANs)
+	}
+	for _, bridge := range s.Bridges {
+		add(bridge.LinkConfig, bridge.VLANs)
+	}
+
+	return prefixes
+}
+
+// MachinePhase is a simple, high-level summary of the lifecycle of a Machine.
+// +kubebuilder:validation:Enum=Registered;Provisioning;Ready;Decommissioned
+type MachinePhase string
+
+const (
+	// MachinePhaseRegistered means that the machine is part of the
+	// inventory, but it has not been seen on the network yet.
+	MachinePhaseRegistered MachinePhase = "Registered"
+	// MachinePhaseProvisioning means that the machine has been seen on
+	// the network, but it is not ready yet.
+	MachinePhaseProvisioning MachinePhase = "Provisioning"
+	// MachinePhaseReady means that the machine is ready to host workloads.
+	MachinePhaseReady MachinePhase = "Ready"
+	// MachinePhaseDecommissioned means that the machine has been retired.
+	MachinePhaseDecommissioned MachinePhase = "Decommissioned"
+)
+
+const (
+	// MachineConditionReady indicates that the machine is ready to host workloads.
+	MachineConditionReady = "Ready"
+	// MachineConditionReachable indicates that the machine has recently been seen on the network.
+	MachineConditionReachable = "Reachable"
+	// MachineConditionProvisioned indicates that an operating system is installed on the machine.
+	MachineConditionProvisioned = "Provisioned"
+	// MachineConditionPowerManaged indicates that the power of the machine is managed by the operator.
+	MachineConditionPowerManaged = "PowerManaged"
+)
+
+// InterfaceStatus describes the observed state of a network interface.
+type InterfaceStatus struct {
+	// MAC is the MAC address of the interface.
+	// +kubebuilder:validation:Required
+	MAC MAC `json:"mac"`
+	// Addresses are the IP addresses that were observed on the interface.
+	// +optional
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// MachinePowerStatus describes the observed power state of a Machine.
+type MachinePowerStatus struct {
+	// State is the observed power state of the machine.
+	// +kubebuilder:validation:Enum=On;Off;Unknown
+	// +optional
+	State PowerState `json:"state,omitempty"`
+	// LastObserved is the last time the power state was observed.
+	// +optional
+	LastObserved *metav1.Time `json:"lastObserved,omitempty"`
+	// LastTransition is the last time the power state was changed by the controller.
+	// +optional
+	LastTransition *metav1.Time `json:"lastTransition,omitempty"`
+	// Error is the error of the last attempt to observe or to change the power state.
+	// +optional
+	Error string `json:"error,omitempty"`
 }
 
 // MachineStatus defines the observed state of a Machine.
 type MachineStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Phase is a simple, high-level summary of the lifecycle of the machine.
+	// +optional
+	Phase MachinePhase `json:"phase,omitempty"`
+	// Conditions describe the current state of the machine.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Interfaces are the observed IP addresses per network interface.
+	// +optional
+	Interfaces []InterfaceStatus `json:"interfaces,omitempty"`
+	// LastSeen is the last time the machine was seen on the network.
+	// +optional
+	LastSeen *metav1.Time `json:"lastSeen,omitempty"`
+	// OSVersion is the version of the installed operating system.
+	// +optional
+	OSVersion string `json:"osVersion,omitempty"`
+	// Power is the observed power state of the machine.
+	// +optional
+	Power *MachinePowerStatus `json:"power,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Power",type=string,JSONPath=`.status.power.state`
+// +kubebuilder:printcolumn:name="OS",type=string,JSONPath=`.status.osVersion`
+// +kubebuilder:printcolumn:name="Last Seen",type=date,JSONPath=`.status.lastSeen`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Machine defines a physical asset that can be used to provision infrastructure.
 type Machine struct {
@@ -17,43 +17,150 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // EDIT THIS FILE! THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required. Any new fields you add
 // must have json tags for the fields to be serialized.
 
-// Selector is a simple label selector that matches labels based on a map
-// of key-value pairs.
+// SelectorOperator is the relationship of a label key to a set of values.
+// +kubebuilder:validation:Enum=In;NotIn;Exists;DoesNotExist
+type SelectorOperator string
+
+const (
+	// SelectorOpIn matches if the label value is in the set of values.
+	SelectorOpIn SelectorOperator = "In"
+	// SelectorOpNotIn matches if the label is missing or its value
+	// is not in the set of values.
+	SelectorOpNotIn SelectorOperator = "NotIn"
+	// SelectorOpExists matches if the label exists.
+	SelectorOpExists SelectorOperator = "Exists"
+	// SelectorOpDoesNotExist matches if the label does not exist.
+	SelectorOpDoesNotExist SelectorOperator = "DoesNotExist"
+)
+
+// SelectorRequirement is a selector that contains values, a key,
+// and an operator that relates the key and values.
+// +kubebuilder:validation:XValidation:rule="self.operator in ['In', 'NotIn'] ? has(self.values) && size(self.values) > 0 : !has(self.values) || size(self.values) == 0",message="values must be set for In and NotIn and must be empty for Exists and DoesNotExist"
+type SelectorRequirement struct {
+	// Key is the label key that the selector applies to.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+	// Operator represents the relationship of the key to the set of values.
+	// +kubebuilder:validation:Required
+	Operator SelectorOperator `json:"operator"`
+	// Values is an array of string values. It must be non-empty for the
+	// operators In and NotIn and it must be empty for the operators
+	// Exists and DoesNotExist.
+	// +optional
+	Values []string `json:"values,omitempty"`
+}
+
+// Selector is a label selector that matches labels based on a map of
+// key-value pairs and a list of expressions. The requirements of
+// matchLabels and matchExpressions are ANDed. An empty selector
+// matches all objects.
 type Selector struct {
 	// MatchLabels is a map of {key,value} pairs. A single {key,value}
 	// in the matchLabels map is equivalent to an element of matchExpressions,
-	// whose key field is "key", the operator is "Equals", and the values array
+	// whose key field is "key", the operator is "In", and the values array
 	// contains only "value". The requirements are ANDed.
-	// +kubebuilder:validation:Required
+	// +optional
 	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+	// MatchExpressions is a list of label selector requirements.
+	// The requirements are ANDed.
+	// +optional
+	MatchExpressions []SelectorRequirement `json:"matchExpressions,omitempty"`
+}
+
+// AsSelector converts the Selector into a labels.Selector that
+// can be used to match labels or to query the Kubernetes API.
+func (s *Selector) AsSelector() (labels.Selector, error) {
+	labelSelector := &metav1.LabelSelector{
+		MatchLabels: s.MatchLabels,
+	}
+
+	for _, requirement := range s.MatchExpressions {
+		labelSelector.MatchExpressions = append(labelSelector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      requirement.Key,
+			Operator: metav1.LabelSelectorOperator(requirement.Operator),
+			Values:   requirement.Values,
+		})
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+
+	return selector, nil
+}
+
+// ParseSelector parses a selector in the string representation used by
+// the Kubernetes API, e.g. "environment in (production),tier!=frontend".
+func ParseSelector(selector string) (*Selector, error) {
+	labelSelector, err := metav1.ParseToLabelSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+
+	result := &Selector{
+		MatchLabels: labelSelector.MatchLabels,
+	}
+	for _, requirement := range labelSelector.MatchExpressions {
+		result.MatchExpressions = append(result.MatchExpressions, SelectorRequirement{
+			Key:      requirement.Key,
+			Operator: SelectorOperator(requirement.Operator),
+			Values:   requirement.Values,
+		})
+	}
+
+	return result, nil
 }
 
 // MachinePoolSpec defines the desired state of a MachinePool.
 type MachinePoolSpec struct {
 	// Selector is a label query over a set of Machines.
-	// The result of matchLabels and matchFields are ANDed.
+	// The result of matchLabels and matchExpressions are ANDed.
 	// +kubebuilder:validation:Required
 	Selector Selector `json:"selector"`
 }
 
+const (
+	// MachinePoolConditionReady indicates that all machines of the pool are ready.
+	MachinePoolConditionReady = "Ready"
+)
+
 // MachinePoolStatus defines the observed state of a MachinePool.
 type MachinePoolStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
-
-	// TODO: Add fields machineCount and readyMachineCount.
-	// TODO: Add field for machine names.
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// MachineCount is the number of machines matched by the selector.
+	// It is always reported, so that pools without machines show zero.
+	MachineCount int32 `json:"machineCount"`
+	// ReadyMachineCount is the number of matched machines that are ready.
+	ReadyMachineCount int32 `json:"readyMachineCount"`
+	// Machines are the names of the machines matched by the selector.
+	// +optional
+	Machines []string `json:"machines,omitempty"`
+	// Conditions describe the current state of the pool.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Machines",type=integer,JSONPath=`.status.machineCount`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyMachineCount`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // MachinePool is the Schema for the machinepools API
 type MachinePool struct {
@@ -0,0 +1,123 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE! THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required. Any new fields you add
+// must have json tags for the fields to be serialized.
+
+// RegionProvider is the infrastructure provider of a Region.
+// +kubebuilder:validation:Enum=Baremetal
+type RegionProvider string
+
+const (
+	// RegionProviderBaremetal provisions a Region on physical Machines.
+	RegionProviderBaremetal RegionProvider = "Baremetal"
+)
+
+// MachineReference references a Machine by name.
+type MachineReference struct {
+	// Name is the name of the referenced Machine.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// ClusterNetwork configures the network of the Kubernetes cluster of a Region.
+type ClusterNetwork struct {
+	// PodSubnets are the networks of the pods. Defaults to ["10.244.0.0/16"].
+	// +kubebuilder:validation:XValidation:rule="self.all(s, isCIDR(s))",message="podSubnets must be in CIDR notation"
+	// +optional
+	PodSubnets []string `json:"podSubnets,omitempty"`
+	// ServiceSubnets are the networks of the services. Defaults to ["10.96.0.0/12"].
+	// +kubebuilder:validation:XValidation:rule="self.all(s, isCIDR(s))",message="serviceSubnets must be in CIDR notation"
+	// +optional
+	ServiceSubnets []string `json:"serviceSubnets,omitempty"`
+	// DNSDomain is the domain of the services. Defaults to "cluster.local".
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	DNSDomain string `json:"dnsDomain,omitempty"`
+}
+
+// RegionSpecBaremetal defines the configuration of a Region
+// that is provisioned on physical Machines.
+type RegionSpecBaremetal struct {
+	// Controlplanes are the Machines that run the control plane of the region.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Controlplanes []MachineReference `json:"controlplanes"`
+	// Workers are the Machines that only run workloads.
+	// +optional
+	Workers []MachineReference `json:"workers,omitempty"`
+	// Endpoint is the URL of the Kubernetes API of the region,
+	// e.g. "https://lab01.example.com:6443". It usually points to
+	// a virtual IP or a load balancer in front of the control planes.
+	// +kubebuilder:validation:Pattern=`^https://`
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// Network configures the network of the Kubernetes cluster.
+	// +optional
+	Network *ClusterNetwork `json:"network,omitempty"`
+}
+
+// RegionSpec defines the desired state of a Region.
+// +kubebuilder:validation:XValidation:rule="self.provider != 'Baremetal' || has(self.baremetal)",message="baremetal is required if the provider is Baremetal"
+type RegionSpec struct {
+	// Provider is the infrastructure provider of the region.
+	// Exactly the configuration block matching the provider must be set.
+	// +kubebuilder:validation:Required
+	Provider RegionProvider `json:"provider"`
+	// Baremetal is the configuration of the Baremetal provider.
+	// +optional
+	Baremetal *RegionSpecBaremetal `json:"baremetal,omitempty"`
+}
+
+// RegionStatus defines the observed state of a Region.
+type RegionStatus struct {
+	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Provider",type=string,JSONPath=`.spec.provider`
+
+// Region defines a failure domain that hosts a Kubernetes cluster.
+type Region struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RegionSpec   `json:"spec,omitempty"`
+	Status RegionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RegionList contains a list of Region
+type RegionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Region `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Region{}, &RegionList{})
+}
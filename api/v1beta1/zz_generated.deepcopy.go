@@ -21,9 +21,310 @@ limitations under the License.
 package v1beta1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Bond) DeepCopyInto(out *Bond) {
+	*out = *in
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LinkConfig.DeepCopyInto(&out.LinkConfig)
+	if in.VLANs != nil {
+		in, out := &in.VLANs, &out.VLANs
+		*out = make([]VLAN, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Bond.
+func (in *Bond) DeepCopy() *Bond {
+	if in == nil {
+		return nil
+	}
+	out := new(Bond)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Bridge) DeepCopyInto(out *Bridge) {
+	*out = *in
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LinkConfig.DeepCopyInto(&out.LinkConfig)
+	if in.VLANs != nil {
+		in, out := &in.VLANs, &out.VLANs
+		*out = make([]VLAN, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Bridge.
+func (in *Bridge) DeepCopy() *Bridge {
+	if in == nil {
+		return nil
+	}
+	out := new(Bridge)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterNetwork) DeepCopyInto(out *ClusterNetwork) {
+	*out = *in
+	if in.PodSubnets != nil {
+		in, out := &in.PodSubnets, &out.PodSubnets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceSubnets != nil {
+		in, out := &in.ServiceSubnets, &out.ServiceSubnets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNetwork.
+func (in *ClusterNetwork) DeepCopy() *ClusterNetwork {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterNetwork)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Disk) DeepCopyInto(out *Disk) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Disk.
+func (in *Disk) DeepCopy() *Disk {
+	if in == nil {
+		return nil
+	}
+	out := new(Disk)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareCPU) DeepCopyInto(out *HardwareCPU) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareCPU.
+func (in *HardwareCPU) DeepCopy() *HardwareCPU {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareCPU)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareDiskSlot) DeepCopyInto(out *HardwareDiskSlot) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareDiskSlot.
+func (in *HardwareDiskSlot) DeepCopy() *HardwareDiskSlot {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareDiskSlot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareNIC) DeepCopyInto(out *HardwareNIC) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareNIC.
+func (in *HardwareNIC) DeepCopy() *HardwareNIC {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareNIC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareProfile) DeepCopyInto(out *HardwareProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareProfile.
+func (in *HardwareProfile) DeepCopy() *HardwareProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HardwareProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareProfileList) DeepCopyInto(out *HardwareProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HardwareProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareProfileList.
+func (in *HardwareProfileList) DeepCopy() *HardwareProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HardwareProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareProfileReference) DeepCopyInto(out *HardwareProfileReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareProfileReference.
+func (in *HardwareProfileReference) DeepCopy() *HardwareProfileReference {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareProfileReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareProfileSpec) DeepCopyInto(out *HardwareProfileSpec) {
+	*out = *in
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		*out = new(HardwareCPU)
+		**out = **in
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.NICs != nil {
+		in, out := &in.NICs, &out.NICs
+		*out = make([]HardwareNIC, len(*in))
+		copy(*out, *in)
+	}
+	if in.DiskSlots != nil {
+		in, out := &in.DiskSlots, &out.DiskSlots
+		*out = make([]HardwareDiskSlot, len(*in))
+		copy(*out, *in)
+	}
+	if in.Talos != nil {
+		in, out := &in.Talos, &out.Talos
+		*out = new(HardwareTalos)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareProfileSpec.
+func (in *HardwareProfileSpec) DeepCopy() *HardwareProfileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareProfileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareProfileStatus) DeepCopyInto(out *HardwareProfileStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareProfileStatus.
+func (in *HardwareProfileStatus) DeepCopy() *HardwareProfileStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareProfileStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareTalos) DeepCopyInto(out *HardwareTalos) {
+	*out = *in
+	if in.Overlay != nil {
+		in, out := &in.Overlay, &out.Overlay
+		*out = new(TalosOverlay)
+		**out = **in
+	}
+	if in.KernelArgs != nil {
+		in, out := &in.KernelArgs, &out.KernelArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareTalos.
+func (in *HardwareTalos) DeepCopy() *HardwareTalos {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareTalos)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Interface) DeepCopyInto(out *Interface) {
 	*out = *in
@@ -32,6 +333,14 @@ func (in *Interface) DeepCopyInto(out *Interface) {
 		*out = make(MAC, len(*in))
 		copy(*out, *in)
 	}
+	in.LinkConfig.DeepCopyInto(&out.LinkConfig)
+	if in.VLANs != nil {
+		in, out := &in.VLANs, &out.VLANs
+		*out = make([]VLAN, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Interface.
@@ -44,6 +353,51 @@ func (in *Interface) DeepCopy() *Interface {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InterfaceStatus) DeepCopyInto(out *InterfaceStatus) {
+	*out = *in
+	if in.MAC != nil {
+		in, out := &in.MAC, &out.MAC
+		*out = make(MAC, len(*in))
+		copy(*out, *in)
+	}
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterfaceStatus.
+func (in *InterfaceStatus) DeepCopy() *InterfaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InterfaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LinkConfig) DeepCopyInto(out *LinkConfig) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinkConfig.
+func (in *LinkConfig) DeepCopy() *LinkConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LinkConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in MAC) DeepCopyInto(out *MAC) {
 	{
@@ -69,7 +423,7 @@ func (in *Machine) DeepCopyInto(out *Machine) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Machine.
@@ -128,7 +482,7 @@ func (in *MachinePool) DeepCopyInto(out *MachinePool) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachinePool.
@@ -200,6 +554,18 @@ func (in *MachinePoolSpec) DeepCopy() *MachinePoolSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachinePoolStatus) DeepCopyInto(out *MachinePoolStatus) {
 	*out = *in
+	if in.Machines != nil {
+		in, out := &in.Machines, &out.Machines
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachinePoolStatus.
@@ -212,10 +578,48 @@ func (in *MachinePoolStatus) DeepCopy() *MachinePoolStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachinePowerStatus) DeepCopyInto(out *MachinePowerStatus) {
+	*out = *in
+	if in.LastObserved != nil {
+		in, out := &in.LastObserved, &out.LastObserved
+		*out = (*in).DeepCopy()
+	}
+	if in.LastTransition != nil {
+		in, out := &in.LastTransition, &out.LastTransition
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachinePowerStatus.
+func (in *MachinePowerStatus) DeepCopy() *MachinePowerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MachinePowerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineReference) DeepCopyInto(out *MachineReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineReference.
+func (in *MachineReference) DeepCopy() *MachineReference {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineSpec) DeepCopyInto(out *MachineSpec) {
 	*out = *in
-	out.Hardware = in.Hardware
+	in.Hardware.DeepCopyInto(&out.Hardware)
 	if in.Interfaces != nil {
 		in, out := &in.Interfaces, &out.Interfaces
 		*out = make([]Interface, len(*in))
@@ -223,6 +627,32 @@ func (in *MachineSpec) DeepCopyInto(out *MachineSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Bonds != nil {
+		in, out := &in.Bonds, &out.Bonds
+		*out = make([]Bond, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Bridges != nil {
+		in, out := &in.Bridges, &out.Bridges
+		*out = make([]Bridge, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = make([]Disk, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Power != nil {
+		in, out := &in.Power, &out.Power
+		*out = new(MachineSpecPower)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineSpec.
@@ -238,6 +668,12 @@ func (in *MachineSpec) DeepCopy() *MachineSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineSpecHardware) DeepCopyInto(out *MachineSpecHardware) {
 	*out = *in
+	if in.ProfileRef != nil {
+		in, out := &in.ProfileRef, &out.ProfileRef
+		*out = new(HardwareProfileReference)
+		**out = **in
+	}
+	in.HardwareProfileSpec.DeepCopyInto(&out.HardwareProfileSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineSpecHardware.
@@ -250,9 +686,52 @@ func (in *MachineSpecHardware) DeepCopy() *MachineSpecHardware {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineSpecPower) DeepCopyInto(out *MachineSpecPower) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(SecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineSpecPower.
+func (in *MachineSpecPower) DeepCopy() *MachineSpecPower {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineSpecPower)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineStatus) DeepCopyInto(out *MachineStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = make([]InterfaceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastSeen != nil {
+		in, out := &in.LastSeen, &out.LastSeen
+		*out = (*in).DeepCopy()
+	}
+	if in.Power != nil {
+		in, out := &in.Power, &out.Power
+		*out = new(MachinePowerStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineStatus.
@@ -265,6 +744,145 @@ func (in *MachineStatus) DeepCopy() *MachineStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Region) DeepCopyInto(out *Region) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Region.
+func (in *Region) DeepCopy() *Region {
+	if in == nil {
+		return nil
+	}
+	out := new(Region)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Region) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegionList) DeepCopyInto(out *RegionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Region, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegionList.
+func (in *RegionList) DeepCopy() *RegionList {
+	if in == nil {
+		return nil
+	}
+	out := new(RegionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RegionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegionSpec) DeepCopyInto(out *RegionSpec) {
+	*out = *in
+	if in.Baremetal != nil {
+		in, out := &in.Baremetal, &out.Baremetal
+		*out = new(RegionSpecBaremetal)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegionSpec.
+func (in *RegionSpec) DeepCopy() *RegionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RegionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegionSpecBaremetal) DeepCopyInto(out *RegionSpecBaremetal) {
+	*out = *in
+	if in.Controlplanes != nil {
+		in, out := &in.Controlplanes, &out.Controlplanes
+		*out = make([]MachineReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Workers != nil {
+		in, out := &in.Workers, &out.Workers
+		*out = make([]MachineReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Network != nil {
+		in, out := &in.Network, &out.Network
+		*out = new(ClusterNetwork)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegionSpecBaremetal.
+func (in *RegionSpecBaremetal) DeepCopy() *RegionSpecBaremetal {
+	if in == nil {
+		return nil
+	}
+	out := new(RegionSpecBaremetal)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegionStatus) DeepCopyInto(out *RegionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegionStatus.
+func (in *RegionStatus) DeepCopy() *RegionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RegionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretReference) DeepCopyInto(out *SecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretReference.
+func (in *SecretReference) DeepCopy() *SecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Selector) DeepCopyInto(out *Selector) {
 	*out = *in
@@ -275,6 +893,13 @@ func (in *Selector) DeepCopyInto(out *Selector) {
 			(*out)[key] = val
 		}
 	}
+	if in.MatchExpressions != nil {
+		in, out := &in.MatchExpressions, &out.MatchExpressions
+		*out = make([]SelectorRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Selector.
@@ -286,3 +911,54 @@ func (in *Selector) DeepCopy() *Selector {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelectorRequirement) DeepCopyInto(out *SelectorRequirement) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelectorRequirement.
+func (in *SelectorRequirement) DeepCopy() *SelectorRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(SelectorRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosOverlay) DeepCopyInto(out *TalosOverlay) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosOverlay.
+func (in *TalosOverlay) DeepCopy() *TalosOverlay {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosOverlay)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VLAN) DeepCopyInto(out *VLAN) {
+	*out = *in
+	in.LinkConfig.DeepCopyInto(&out.LinkConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VLAN.
+func (in *VLAN) DeepCopy() *VLAN {
+	if in == nil {
+		return nil
+	}
+	out := new(VLAN)
+	in.DeepCopyInto(out)
+	return out
+}
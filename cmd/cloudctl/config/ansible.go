@@ -0,0 +1,141 @@
+package config
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// AnsibleGroupAll is the group that contains every host of an Ansible inventory.
+const AnsibleGroupAll = "all"
+
+// invalidAnsibleGroupChars matches the characters that Ansible does not allow in group names.
+var invalidAnsibleGroupChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// AnsibleInventory is a dynamic inventory in the JSON format that
+// inventory scripts print when they are invoked with --list.
+// Reference: https://docs.ansible.com/ansible/latest/dev_guide/developing_inventory.html
+type AnsibleInventory struct {
+	// Groups are the groups of the inventory by their name.
+	Groups map[string]*AnsibleGroup
+	// HostVars are the variables of the hosts by their name.
+	HostVars map[string]*AnsibleHostVars
+}
+
+// AnsibleGroup is a group of hosts.
+type AnsibleGroup struct {
+	Hosts    []string `json:"hosts,omitempty"`
+	Children []string `json:"children,omitempty"`
+}
+
+// AnsibleHostVars are the variables of a host that are derived from its Machine.
+type AnsibleHostVars struct {
+	AnsibleHost string            `json:"ansible_host,omitempty"`
+	Vendor      string            `json:"vendor,omitempty"`
+	Model       string            `json:"model,omitempty"`
+	MACs        []string          `json:"macs"`
+	Addresses   []string          `json:"addresses,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// MarshalJSON encodes the inventory with the host variables in the _meta
+// key, so that Ansible does not need to invoke the script for every host.
+func (i *AnsibleInventory) MarshalJSON() ([]byte, error) {
+	document := make(map[string]any, len(i.Groups)+1)
+	for name, group := range i.Groups {
+		document[name] = group
+	}
+	document["_meta"] = map[string]any{"hostvars": i.HostVars}
+
+	return json.Marshal(document)
+}
+
+// AnsibleInventory returns the inventory of all Machines that are not
+// decommissioned. Every MachinePool becomes a group prefixed with "pool_"
+// and every label becomes a group prefixed with "label_", e.g. the label
+// "topology.kubernetes.io/zone=home" becomes "label_topology_kubernetes_io_zone_home".
+// The hardware profiles must be resolved before.
+func (r *ConfigRepository) AnsibleInventory() (*AnsibleInventory, error) {
+	inventory := &AnsibleInventory{
+		Groups:   make(map[string]*AnsibleGroup),
+		HostVars: make(map[string]*AnsibleHostVars),
+	}
+
+	addHost := func(group string, host string) {
+		if inventory.Groups[group] == nil {
+			inventory.Groups[group] = &AnsibleGroup{}
+		}
+		inventory.Groups[group].Hosts = append(inventory.Groups[group].Hosts, host)
+	}
+
+	for index := range r.Machines.Items {
+		machine := &r.Machines.Items[index]
+		if machine.Spec.Decommissioned {
+			continue
+		}
+
+		hostVars := &AnsibleHostVars{
+			Vendor: machine.Spec.Hardware.Vendor,
+			Model:  machine.Spec.Hardware.Model,
+			MACs:   make([]string, 0, len(machine.Spec.Interfaces)),
+			Labels: machine.Labels,
+		}
+		for _, iface := range machine.Spec.Interfaces {
+			hostVars.MACs = append(hostVars.MACs, iface.MAC.String())
+		}
+		for _, addr := range MachineAddresses(machine) {
+			hostVars.Addresses = append(hostVars.Addresses, addr.String())
+		}
+		if len(hostVars.Addresses) > 0 {
+			hostVars.AnsibleHost = hostVars.Addresses[0]
+		}
+		inventory.HostVars[machine.Name] = hostVars
+
+		addHost(AnsibleGroupAll, machine.Name)
+		for key, value := range machine.Labels {
+			addHost(ansibleGroupName("label", key, value), machine.Name)
+		}
+	}
+
+	for index := range r.MachinePools.Items {
+		pool := &r.MachinePools.Items[index]
+
+		machines, err := r.MachinesForPool(pool)
+		if err != nil {
+			return nil, err
+		}
+
+		group := ansibleGroupName("pool", pool.Name)
+		inventory.Groups[group] = &AnsibleGroup{}
+		for _, machine := range machines {
+			if _, ok := inventory.HostVars[machine.Name]; ok {
+				addHost(group, machine.Name)
+			}
+		}
+	}
+
+	all := inventory.Groups[AnsibleGroupAll]
+	if all == nil {
+		all = &AnsibleGroup{}
+		inventory.Groups[AnsibleGroupAll] = all
+	}
+	for name, group := range inventory.Groups {
+		sort.Strings(group.Hosts)
+		group.Hosts = slices.Compact(group.Hosts)
+
+		if name != AnsibleGroupAll {
+			all.Children = append(all.Children, name)
+		}
+	}
+	sort.Strings(all.Children)
+
+	return inventory, nil
+}
+
+// ansibleGroupName joins the parts with underscores and replaces
+// all characters that are not valid in group names with underscores.
+func ansibleGroupName(parts ...string) string {
+	return invalidAnsibleGroupChars.ReplaceAllString(strings.Join(parts, "_"), "_")
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AnsibleInventory", func() {
+	var inventory *AnsibleInventory
+
+	BeforeEach(func() {
+		var err error
+		inventory, err = loadExportRepository().AnsibleInventory()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should render the inventory", func() {
+		data, err := json.MarshalIndent(inventory, "", "  ")
+		Expect(err).NotTo(HaveOccurred())
+		expectGolden("ansible.json", append(data, '\n'))
+	})
+
+	It("should add the host variables of the machines to _meta", func() {
+		Expect(inventory.HostVars).To(HaveLen(3))
+		Expect(inventory.HostVars).NotTo(HaveKey("dog"))
+		Expect(inventory.HostVars["ant"]).To(Equal(&AnsibleHostVars{
+			AnsibleHost: "10.0.0.10",
+			Vendor:      "FriendlyElec",
+			Model:       "NanoPiR5S",
+			MACs:        []string{"02:00:00:00:00:01", "02:00:00:00:00:0a"},
+			Addresses:   []string{"10.0.0.10", "fd00::10"},
+			Labels:      map[string]string{"pool": "lab01", "topology.kubernetes.io/zone": "home"},
+		}))
+		Expect(inventory.HostVars["cow"].AnsibleHost).To(BeEmpty())
+	})
+
+	DescribeTable("should group the machines",
+		func(group string, hosts []string) {
+			Expect(inventory.Groups).To(HaveKey(group))
+			Expect(inventory.Groups[group].Hosts).To(Equal(hosts))
+		},
+		Entry("in all", "all", []string{"ant", "bee", "cow"}),
+		Entry("by pool", "pool_lab01", []string{"ant", "bee"}),
+		Entry("by overlapping pool", "pool_office", []string{"bee"}),
+		Entry("by label", "label_pool_lab01", []string{"ant", "bee"}),
+		Entry("by label with prefix", "label_topology_kubernetes_io_zone_home", []string{"ant", "cow"}),
+	)
+
+	It("should add all other groups as children of all", func() {
+		Expect(inventory.Groups["all"].Children).To(Equal([]string{
+			"label_pool_lab01",
+			"label_topology_kubernetes_io_zone_home",
+			"label_topology_kubernetes_io_zone_office",
+			"pool_lab01",
+			"pool_office",
+		}))
+	})
+})
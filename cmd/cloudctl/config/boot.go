@@ -0,0 +1,131 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+)
+
+// TalosReleaseURL is the base URL of the release assets of Talos.
+const TalosReleaseURL = "https://github.com/siderolabs/talos/releases/download"
+
+// BootScriptFileUnknown is the path of the iPXE script for unknown machines.
+const BootScriptFileUnknown = "boot/unknown.ipxe"
+
+// talosKernelArgs are the kernel arguments that Talos requires on bare metal.
+// Reference: https://www.talos.dev/latest/reference/kernel/
+var talosKernelArgs = []string{
+	"talos.platform=metal",
+	"console=tty0",
+	"init_on_alloc=1",
+	"slab_nomerge",
+	"pti=on",
+	"consoleblank=0",
+	"nvme_core.io_timeout=4294967295",
+	"printk.devkmsg=on",
+	"ima_template=ima-ng",
+	"ima_appraise=fix",
+	"ima_hash=sha512",
+}
+
+// bootIndexScript chains the script of the booting machine. The variable
+// netX/mac is formatted just like the MAC addresses in the file names. The
+// paths start with "./", because a MAC address could be mistaken for a URL
+// scheme. If no script exists, the script for unknown machines is used.
+const bootIndexScript = `#!ipxe
+chain --autofree ./${netX/mac}.ipxe || chain --autofree ./unknown.ipxe
+`
+
+// bootUnknownScript is served to machines that are not in the inventory.
+// It exits so that the firmware continues with the next boot device.
+const bootUnknownScript = `#!ipxe
+echo Machine with MAC address ${netX/mac} is not in the inventory.
+echo Continuing with the next boot device in 10 seconds.
+sleep 10
+exit 1
+`
+
+// bootMachineTemplate boots Talos on a known machine.
+var bootMachineTemplate = template.Must(template.New("machine").Parse(`#!ipxe
+echo Booting Talos {{ .Version }} on {{ .Machine }} ({{ .Architecture }})
+kernel {{ .Kernel }} initrd=initramfs.xz {{ .KernelArgs }}
+initrd --name initramfs.xz {{ .Initramfs }}
+boot
+`))
+
+// BootScriptFile returns the path of the iPXE script for the given MAC address.
+func BootScriptFile(mac cloud.MAC) string {
+	return path.Join("boot", mac.String()+".ipxe")
+}
+
+// renderBootScripts renders an iPXE script for every interface of every
+// Machine that boots the pinned version of Talos. The scripts can only be
+// rendered if the version of Talos is known. Duplicate MAC addresses are
+// handled like in renderMACs.
+func (r *ConfigRepository) renderBootScripts(artifacts Artifacts) error {
+	if r.Settings == nil || r.Settings.Talos.Version == "" {
+		return nil
+	}
+
+	artifacts[path.Join("boot", "index.ipxe")] = []byte(bootIndexScript)
+	artifacts[BootScriptFileUnknown] = []byte(bootUnknownScript)
+
+	for index := range r.Machines.Items {
+		machine := &r.Machines.Items[index]
+
+		script, err := r.bootScript(machine)
+		if err != nil {
+			return fmt.Errorf("failed to render boot script for machine %s: %w", machine.Name, err)
+		}
+
+		for _, iface := range machine.Spec.Interfaces {
+			file := BootScriptFile(iface.MAC)
+			if _, ok := artifacts[file]; ok {
+				continue
+			}
+
+			artifacts[file] = script
+		}
+	}
+
+	return nil
+}
+
+// bootScript renders the iPXE script of a Machine. The hardware of the
+// Machine must already be merged with its HardwareProfile.
+func (r *ConfigRepository) bootScript(machine *cloud.Machine) ([]byte, error) {
+	hardware := machine.Spec.Hardware
+
+	// Most machines in a homelab are still x86, so this is the safest default.
+	architecture := cloud.ArchitectureAMD64
+	if hardware.CPU != nil && hardware.CPU.Architecture != "" {
+		architecture = hardware.CPU.Architecture
+	}
+
+	kernelArgs := append([]string{}, talosKernelArgs...)
+	if hardware.Talos != nil {
+		kernelArgs = append(kernelArgs, hardware.Talos.KernelArgs...)
+	}
+
+	version := r.Settings.Talos.Version
+	releaseURL := fmt.Sprintf("%s/%s", TalosReleaseURL, version)
+
+	buffer := &bytes.Buffer{}
+	err := bootMachineTemplate.Execute(buffer, map[string]string{
+		"Version":      version,
+		"Machine":      machine.Name,
+		"Architecture": string(architecture),
+		"Kernel":       fmt.Sprintf("%s/vmlinuz-%s", releaseURL, architecture),
+		"Initramfs":    fmt.Sprintf("%s/initramfs-%s.xz", releaseURL, architecture),
+		"KernelArgs":   strings.Join(kernelArgs, " "),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
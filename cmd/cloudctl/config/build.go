@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path"
@@ -9,18 +10,21 @@ import (
 	"github.com/nicklasfrahm/cloud/pkg/kubeenc"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
 )
 
 // BuildCommand returns the build command.
 func BuildCommand() *cobra.Command {
+	var settingsFile string
+
 	cmd := &cobra.Command{
 		Use:   "build <src_dir> <dst_dir>",
 		Short: "Build configuration into static files",
 		Long: `Build configuration into static files
-that can be served by a web server.`,
+that can be served by a web server.
+
+If the settings file pins a version of Talos, an iPXE
+boot script is built for every machine.`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 2 {
@@ -30,19 +34,14 @@ that can be served by a web server.`,
 			inputDir := args[0]
 			outputDir := args[1]
 
-			repository := NewConfigRepository()
-
-			schemas := map[string]ResourceLoader{
-				"machines": Load(&repository.Machines.Items),
-				"machinepools": Load(&repository.MachinePools.Items),
+			repository, err := LoadConfigRepository(inputDir)
+			if err != nil {
+				return err
 			}
 
-			for schema, load := range schemas {
-				schemaDir := path.Join(inputDir, schema)
-
-				if err := load(schemaDir); err != nil {
-					return fmt.Errorf("failed to load schema: %w", err)
-				}
+			repository.Settings, err = LoadSettings(settingsFile)
+			if err != nil {
+				return err
 			}
 
 			versionDir := path.Join(outputDir, cloud.GroupVersion.Version)
@@ -54,101 +53,170 @@ that can be served by a web server.`,
 		},
 	}
 
+	cmd.Flags().StringVar(&settingsFile, "config", DefaultSettingsFile, "settings file that pins versions, e.g. of Talos")
+
 	return cmd
 }
 
+// Artifacts are the rendered files of the static API. The keys are the
+// paths of the files relative to the version directory of the API.
+type Artifacts map[string][]byte
+
+// Add encodes a resource and adds it as file with the given path.
+func (a Artifacts) Add(file string, resource runtime.Object) error {
+	data, err := Encode(resource)
+	if err != nil {
+		return err
+	}
+
+	a[file] = data
 
-// ConfigRepository is a configuration repository.
-type ConfigRepository struct {
-	Machines cloud.MachineList
-	MachinePools cloud.MachinePoolList
+	return nil
 }
 
-// NewConfigRepository creates a new configuration repository.
-func NewConfigRepository() *ConfigRepository {
-	return &ConfigRepository{
-		Machines: cloud.MachineList{
-			Items: []cloud.Machine{},
-		},
-		MachinePools: cloud.MachinePoolList{
-			Items: []cloud.MachinePool{},
-		},
+// Encode encodes a resource as JSON.
+func Encode(resource runtime.Object) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	encoder := kubeenc.NewJSONEncoder(buffer)
+
+	cloudScheme, err := cloud.SchemeBuilder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build scheme: %w", err)
 	}
+
+	if _, err := encoder.EncodeWithScheme(resource, cloudScheme); err != nil {
+		return nil, fmt.Errorf("failed to encode resource: %w", err)
+	}
+
+	return buffer.Bytes(), nil
 }
 
-// ResourceLoader is a function that loads a resource into a repository.
-type ResourceLoader func(srcDir string) error
+// Write writes all artifacts into a directory.
+func (a Artifacts) Write(dstDir string) error {
+	for file, data := range a {
+		dstFile := path.Join(dstDir, file)
 
-// Load loads the configuration. This is not optimized for performance,
-// we should most likely make this concurrent.
-func Load[T any](repository *[]T) ResourceLoader {
-	return func(schemaDir string) error {
-		// Read files.
-		entries, err := os.ReadDir(schemaDir)
-		if err != nil {
-			return fmt.Errorf("failed to read schema directory: %w", err)
+		if err := os.MkdirAll(path.Dir(dstFile), 0755); err != nil {
+			return fmt.Errorf("failed to create destination directory: %w", err)
 		}
 
-		for _, entry := range entries {
-			// We do not expect subdirectories.
-			if entry.IsDir() {
-				continue
-			}
+		if err := os.WriteFile(dstFile, data, 0644); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+	}
 
-			resourceManifest := path.Join(schemaDir, entry.Name())
+	return nil
+}
 
-			rawResource, err := os.ReadFile(resourceManifest)
-			if err != nil {
-				return fmt.Errorf("failed to read resource: %w", err)
-			}
+// Render resolves the references of the configuration repository
+// and renders it into the files of the static API.
+func (r *ConfigRepository) Render() (Artifacts, error) {
+	if err := r.ResolveHardwareProfiles(); err != nil {
+		return nil, fmt.Errorf("failed to resolve hardware profiles: %w", err)
+	}
 
-			decoder := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
+	if err := r.ResolveMachinePools(); err != nil {
+		return nil, fmt.Errorf("failed to resolve machine pools: %w", err)
+	}
 
-			obj, _, err := decoder.Decode(rawResource, nil, nil)
-			if err != nil {
-				return fmt.Errorf("failed to decode resource manifest: %w", err)
-			}
+	for index := range r.Machines.Items {
+		// Every machine in the repository has at least been registered.
+		if r.Machines.Items[index].Status.Phase == "" {
+			r.Machines.Items[index].Status.Phase = cloud.MachinePhaseRegistered
+		}
+	}
 
-			entity := new(T)
+	artifacts := make(Artifacts)
+	for _, schema := range Schemas {
+		if err := schema.Render(r, artifacts); err != nil {
+			return nil, fmt.Errorf("failed to render schema: %w", err)
+		}
+	}
 
-			err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).UnstructuredContent(), entity)
-			if err != nil {
-				return fmt.Errorf("failed to convert Kubernetes manifest: %w", err)
-			}
+	if err := r.renderMemberships(artifacts); err != nil {
+		return nil, fmt.Errorf("failed to render machine pool memberships: %w", err)
+	}
 
-			*repository = append(*repository, *entity)
-		}
+	if err := r.renderMACs(artifacts); err != nil {
+		return nil, fmt.Errorf("failed to render MAC address lookup: %w", err)
+	}
 
-		return nil
+	if err := r.renderBootScripts(artifacts); err != nil {
+		return nil, fmt.Errorf("failed to render boot scripts: %w", err)
 	}
-}
 
-// Build builds a resource into a file.
-func Build[T runtime.Object](dstFile string, resource T) error {
-	if err := os.MkdirAll(path.Dir(dstFile), 0755); err != nil {
-		return fmt.Errorf("failed to create destination directory: %w", err)
+	if err := r.renderPrometheus(artifacts); err != nil {
+		return nil, fmt.Errorf("failed to render Prometheus targets: %w", err)
 	}
 
-	file, err := os.Create(dstFile)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+	return artifacts, nil
+}
+
+// MACLookupFile returns the path of the file that contains
+// the Machine owning an interface with the given MAC address.
+func MACLookupFile(mac cloud.MAC) string {
+	return path.Join("by-mac", mac.String()+".json")
+}
+
+// renderMACs renders the owning Machine of every interface by its MAC address,
+// so that netboot clients can discover their identity. If a MAC address is not
+// unique, the first Machine wins, as duplicates are reported by the validation.
+func (r *ConfigRepository) renderMACs(artifacts Artifacts) error {
+	for index := range r.Machines.Items {
+		machine := &r.Machines.Items[index]
+
+		for _, iface := range machine.Spec.Interfaces {
+			file := MACLookupFile(iface.MAC)
+			if _, ok := artifacts[file]; ok {
+				continue
+			}
+
+			if err := artifacts.Add(file, machine); err != nil {
+				return err
+			}
+		}
 	}
-	defer file.Close()
 
-	encoder := kubeenc.NewJSONEncoder(file)
+	return nil
+}
 
-	cloudScheme, err := cloud.SchemeBuilder.Build()
-	if err != nil {
-		return fmt.Errorf("failed to build scheme: %w", err)
+// renderMemberships renders the Machines of every MachinePool and the
+// MachinePools of every Machine, so that static clients can look up pool
+// memberships without evaluating selectors. A resource without members
+// is rendered as an empty list to distinguish it from an unknown resource.
+func (r *ConfigRepository) renderMemberships(artifacts Artifacts) error {
+	pools := make(map[string]*cloud.MachinePoolList, len(r.Machines.Items))
+	for _, machine := range r.Machines.Items {
+		pools[machine.Name] = &cloud.MachinePoolList{
+			Items: []cloud.MachinePool{},
+		}
 	}
 
-	data, err := encoder.EncodeWithScheme(resource, cloudScheme)
-	if err != nil {
-		return fmt.Errorf("failed to encode resource: %w", err)
+	for index := range r.MachinePools.Items {
+		pool := &r.MachinePools.Items[index]
+
+		members, err := r.MachinesForPool(pool)
+		if err != nil {
+			return err
+		}
+
+		machines := &cloud.MachineList{
+			Items: make([]cloud.Machine, 0, len(members)),
+		}
+		for _, machine := range members {
+			machines.Items = append(machines.Items, *machine)
+			pools[machine.Name].Items = append(pools[machine.Name].Items, *pool)
+		}
+
+		if err := artifacts.Add(path.Join("machinepools", pool.Name, "machines.json"), machines); err != nil {
+			return err
+		}
 	}
 
-	if _, err := file.Write(data); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	for name, list := range pools {
+		if err := artifacts.Add(path.Join("machines", name, "machinepools.json"), list); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -156,6 +224,11 @@ func Build[T runtime.Object](dstFile string, resource T) error {
 
 // Build builds the configuration repository into static files.
 func (r *ConfigRepository) Build(dstDir string) error {
+	artifacts, err := r.Render()
+	if err != nil {
+		return err
+	}
+
 	// Clear the destination directory.
 	if err := os.RemoveAll(dstDir); err != nil {
 		// Ignore errors if the directory does not exist.
@@ -169,18 +242,7 @@ func (r *ConfigRepository) Build(dstDir string) error {
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
-	schemas := map[string]ResourceBuilder{
-		"machines": BuildAll(&r.Machines, ToPointerSlice(r.Machines.Items)),
-		"machinepools": BuildAll(&r.MachinePools, ToPointerSlice(r.MachinePools.Items)),
-	}
-
-	for schema, build := range schemas {
-		if err := build(dstDir, schema); err != nil {
-			return fmt.Errorf("failed to build schema: %w", err)
-		}
-	}
-
-	return nil
+	return artifacts.Write(dstDir)
 }
 
 // CRD is a resource that has metadata and can be serialized.
@@ -191,21 +253,20 @@ type CRD interface {
 	GetObjectMeta() metav1.Object
 }
 
-// ResourceBuilder is a function that builds a resource.
-type ResourceBuilder func(dstDir string, schema string) error
+// ResourceRenderer is a function that renders a resource.
+type ResourceRenderer func(artifacts Artifacts, schema string) error
 
-// BuildAll builds a schema into a directory.
-func BuildAll[T runtime.Object, U CRD](list T, items []U) ResourceBuilder {
-	return func(dstDir string, schema string) error {
-		machineIndex := path.Join(dstDir, schema, "index.json")
-		if err := Build(machineIndex, list); err != nil {
-			return fmt.Errorf("failed to build schema index: %w", err)
+// RenderAll renders a schema into an index and a file per resource.
+func RenderAll[T runtime.Object, U CRD](list T, items []U) ResourceRenderer {
+	return func(artifacts Artifacts, schema string) error {
+		if err := artifacts.Add(path.Join(schema, "index.json"), list); err != nil {
+			return fmt.Errorf("failed to render schema index: %w", err)
 		}
 
 		for _, item := range items {
-			machineFile := path.Join(dstDir, schema, item.GetObjectMeta().GetName() +".json")
-			if err := Build(machineFile, item); err != nil {
-				return fmt.Errorf("failed to build schema: %w", err)
+			file := path.Join(schema, item.GetObjectMeta().GetName()+".json")
+			if err := artifacts.Add(file, item); err != nil {
+				return fmt.Errorf("failed to render schema: %w", err)
 			}
 		}
 
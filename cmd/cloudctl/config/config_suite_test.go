@@ -0,0 +1,41 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// update regenerates the golden files instead of comparing with them.
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestConfig(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "config suite")
+}
+
+// expectGolden compares the data with the golden file in testdata/golden.
+func expectGolden(name string, data []byte) {
+	file := path.Join("testdata", "golden", name)
+	if *update {
+		Expect(os.WriteFile(file, data, 0644)).To(Succeed())
+	}
+
+	golden, err := os.ReadFile(file)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(string(data)).To(Equal(string(golden)))
+}
+
+// loadExportRepository loads the repository in testdata/export
+// and resolves the hardware profiles like the export commands.
+func loadExportRepository() *ConfigRepository {
+	repository, err := LoadConfigRepository(path.Join("testdata", "export"))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(repository.ResolveHardwareProfiles()).To(Succeed())
+
+	return repository
+}
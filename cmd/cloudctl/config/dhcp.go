@@ -0,0 +1,305 @@
+package config
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+	"github.com/nicklasfrahm/cloud/pkg/dhcp"
+)
+
+// DHCPFormats are the supported formats of the DHCP configuration.
+var DHCPFormats = []string{"dnsmasq", "kea", "ethers"}
+
+// invalidDHCPTagChars matches the characters that are not allowed in tags of dnsmasq or classes of Kea.
+var invalidDHCPTagChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// DHCP client classes that select the iPXE binary for PXE clients.
+const (
+	dhcpClassIPXE     = "ipxe"
+	dhcpClassBIOS     = "bios"
+	dhcpClassEFI      = "efi_x86_64"
+	dhcpClassARM64EFI = "efi_arm64"
+)
+
+// DHCPOptions configure the chainloading of iPXE in the DHCP configuration.
+type DHCPOptions struct {
+	// ScriptURL is the URL of the iPXE script that is sent to iPXE clients.
+	// The boot options are omitted if it is empty.
+	ScriptURL string
+	// TFTPServer is the address of the server that PXE clients download
+	// iPXE from. Defaults to the DHCP server itself.
+	TFTPServer string
+}
+
+// DHCPHost is the static reservation of an interface of a Machine.
+type DHCPHost struct {
+	// MAC is the MAC address of the interface.
+	MAC cloud.MAC
+	// Addr is the static or observed IPv4 address of the
+	// interface. It is invalid if the interface has no address.
+	Addr netip.Addr
+	// Hostname is the name of the Machine.
+	Hostname string
+	// Tags are the names of the MachinePools of the Machine prefixed
+	// with "pool_", so that pool specific options can be configured.
+	Tags []string
+}
+
+// DHCPHosts returns a reservation for every interface of every Machine that
+// is not decommissioned, sorted by the hostname and MAC address. The address
+// of a reservation is the first static IPv4 address of the interface or the
+// first observed one if the interface has no static IPv4 address.
+func (r *ConfigRepository) DHCPHosts() ([]DHCPHost, error) {
+	tags := make(map[string][]string, len(r.Machines.Items))
+	for index := range r.MachinePools.Items {
+		pool := &r.MachinePools.Items[index]
+
+		machines, err := r.MachinesForPool(pool)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, machine := range machines {
+			tags[machine.Name] = append(tags[machine.Name], dhcpTag("pool", pool.Name))
+		}
+	}
+
+	hosts := make([]DHCPHost, 0, len(r.Machines.Items))
+	for index := range r.Machines.Items {
+		machine := &r.Machines.Items[index]
+		if machine.Spec.Decommissioned {
+			continue
+		}
+
+		slices.Sort(tags[machine.Name])
+		for _, iface := range machine.Spec.Interfaces {
+			hosts = append(hosts, DHCPHost{
+				MAC:      iface.MAC,
+				Addr:     interfaceAddr4(machine, iface.MAC),
+				Hostname: machine.Name,
+				Tags:     tags[machine.Name],
+			})
+		}
+	}
+
+	slices.SortFunc(hosts, func(a, b DHCPHost) int {
+		return cmp.Or(strings.Compare(a.Hostname, b.Hostname), strings.Compare(a.MAC.String(), b.MAC.String()))
+	})
+
+	return hosts, nil
+}
+
+// DHCPConfig renders the reservations of the Machines in the given format.
+// The formats dnsmasq and kea also contain the options to chainload iPXE
+// and tags or client classes for the MachinePools of the Machines, while
+// the format ethers only maps the MAC addresses to addresses or hostnames.
+func (r *ConfigRepository) DHCPConfig(format string, options DHCPOptions) ([]byte, error) {
+	hosts, err := r.DHCPHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "dnsmasq":
+		return dnsmasqConfig(hosts, options), nil
+	case "kea":
+		return keaConfig(hosts, options)
+	case "ethers":
+		return ethersConfig(hosts), nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// dnsmasqConfig renders the reservations as dnsmasq configuration.
+// Reference: https://thekelleys.org.uk/dnsmasq/docs/dnsmasq-man.html
+func dnsmasqConfig(hosts []DHCPHost, options DHCPOptions) []byte {
+	builder := &strings.Builder{}
+	builder.WriteString("# Generated by labctl export dhcp. Do not edit.\n")
+
+	if options.ScriptURL != "" {
+		// The TFTP server of dnsmasq is used if no server is configured.
+		server := ""
+		if options.TFTPServer != "" {
+			server = ",," + options.TFTPServer
+		}
+
+		builder.WriteString("\n")
+		fmt.Fprintf(builder, "dhcp-userclass=set:%s,iPXE\n", dhcpClassIPXE)
+		fmt.Fprintf(builder, "dhcp-match=set:%s,option:client-arch,0\n", dhcpClassBIOS)
+		fmt.Fprintf(builder, "dhcp-match=set:%s,option:client-arch,7\n", dhcpClassEFI)
+		fmt.Fprintf(builder, "dhcp-match=set:%s,option:client-arch,9\n", dhcpClassEFI)
+		fmt.Fprintf(builder, "dhcp-match=set:%s,option:client-arch,11\n", dhcpClassARM64EFI)
+		fmt.Fprintf(builder, "dhcp-boot=tag:%s,%s\n", dhcpClassIPXE, options.ScriptURL)
+		fmt.Fprintf(builder, "dhcp-boot=tag:!%s,tag:%s,%s%s\n", dhcpClassIPXE, dhcpClassBIOS, dhcp.DefaultBootFileBIOS, server)
+		fmt.Fprintf(builder, "dhcp-boot=tag:!%s,tag:%s,%s%s\n", dhcpClassIPXE, dhcpClassEFI, dhcp.DefaultBootFileEFI, server)
+		fmt.Fprintf(builder, "dhcp-boot=tag:!%s,tag:%s,%s%s\n", dhcpClassIPXE, dhcpClassARM64EFI, dhcp.DefaultBootFileARM64, server)
+	}
+
+	if len(hosts) > 0 {
+		builder.WriteString("\n")
+	}
+
+	for _, host := range hosts {
+		fields := []string{host.MAC.String()}
+		for _, tag := range host.Tags {
+			fields = append(fields, "set:"+tag)
+		}
+		if host.Addr.IsValid() {
+			fields = append(fields, host.Addr.String())
+		}
+		fields = append(fields, host.Hostname)
+
+		fmt.Fprintf(builder, "dhcp-host=%s\n", strings.Join(fields, ","))
+	}
+
+	return []byte(builder.String())
+}
+
+// keaClientClass is a client class of the Kea DHCPv4 server.
+type keaClientClass struct {
+	Name         string `json:"name"`
+	Test         string `json:"test,omitempty"`
+	NextServer   string `json:"next-server,omitempty"`
+	BootFileName string `json:"boot-file-name,omitempty"`
+}
+
+// keaReservation is a host reservation of the Kea DHCPv4 server.
+type keaReservation struct {
+	HWAddress     string   `json:"hw-address"`
+	IPAddress     string   `json:"ip-address,omitempty"`
+	Hostname      string   `json:"hostname"`
+	ClientClasses []string `json:"client-classes,omitempty"`
+}
+
+// keaConfig renders the reservations as configuration of the Kea DHCPv4 server.
+// The client classes and reservations are global and need to be merged into
+// the configuration of the server.
+// Reference: https://kea.readthedocs.io/en/latest/arm/dhcp4-srv.html
+func keaConfig(hosts []DHCPHost, options DHCPOptions) ([]byte, error) {
+	classes := make([]keaClientClass, 0)
+	if options.ScriptURL != "" {
+		// PXE clients are matched by their architecture unless they already run iPXE.
+		notIPXE := fmt.Sprintf("not member('%s') and ", dhcpClassIPXE)
+		classes = append(classes,
+			keaClientClass{
+				Name:         dhcpClassIPXE,
+				Test:         "substring(option[77].hex,0,4) == 'iPXE'",
+				BootFileName: options.ScriptURL,
+			},
+			keaClientClass{
+				Name:         dhcpClassBIOS,
+				Test:         notIPXE + "option[93].hex == 0x0000",
+				NextServer:   options.TFTPServer,
+				BootFileName: dhcp.DefaultBootFileBIOS,
+			},
+			keaClientClass{
+				Name:         dhcpClassEFI,
+				Test:         notIPXE + "(option[93].hex == 0x0007 or option[93].hex == 0x0009)",
+				NextServer:   options.TFTPServer,
+				BootFileName: dhcp.DefaultBootFileEFI,
+			},
+			keaClientClass{
+				Name:         dhcpClassARM64EFI,
+				Test:         notIPXE + "option[93].hex == 0x000b",
+				NextServer:   options.TFTPServer,
+				BootFileName: dhcp.DefaultBootFileARM64,
+			},
+		)
+	}
+
+	tags := make([]string, 0)
+	reservations := make([]keaReservation, 0, len(hosts))
+	for _, host := range hosts {
+		reservation := keaReservation{
+			HWAddress:     host.MAC.String(),
+			Hostname:      host.Hostname,
+			ClientClasses: host.Tags,
+		}
+		if host.Addr.IsValid() {
+			reservation.IPAddress = host.Addr.String()
+		}
+		reservations = append(reservations, reservation)
+
+		tags = append(tags, host.Tags...)
+	}
+
+	// Classes that are assigned by reservations must be defined.
+	slices.Sort(tags)
+	for _, tag := range slices.Compact(tags) {
+		classes = append(classes, keaClientClass{Name: tag})
+	}
+
+	data, err := json.MarshalIndent(map[string]any{
+		"Dhcp4": map[string]any{
+			"client-classes": classes,
+			"reservations":   reservations,
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode kea configuration: %w", err)
+	}
+
+	return append(data, '\n'), nil
+}
+
+// ethersConfig renders the reservations in the format of /etc/ethers,
+// which maps MAC addresses to the address or to the hostname.
+func ethersConfig(hosts []DHCPHost) []byte {
+	builder := &strings.Builder{}
+	builder.WriteString("# Generated by labctl export dhcp. Do not edit.\n")
+
+	for _, host := range hosts {
+		target := host.Hostname
+		if host.Addr.IsValid() {
+			target = host.Addr.String()
+		}
+
+		fmt.Fprintf(builder, "%s %s\n", host.MAC.String(), target)
+	}
+
+	return []byte(builder.String())
+}
+
+// interfaceAddr4 returns the first static IPv4 address of the interface of
+// a Machine, the first observed IPv4 address if the interface has no static
+// IPv4 address or an invalid address if no address was observed.
+func interfaceAddr4(machine *cloud.Machine, mac cloud.MAC) netip.Addr {
+	for _, iface := range machine.Spec.Interfaces {
+		if iface.MAC.String() != mac.String() {
+			continue
+		}
+
+		for _, prefix := range iface.Prefixes() {
+			if prefix.Addr().Unmap().Is4() {
+				return prefix.Addr().Unmap()
+			}
+		}
+	}
+
+	for _, iface := range machine.Status.Interfaces {
+		if iface.MAC.String() != mac.String() {
+			continue
+		}
+
+		for _, address := range iface.Addresses {
+			if addr, err := netip.ParseAddr(address); err == nil && addr.Unmap().Is4() {
+				return addr.Unmap()
+			}
+		}
+	}
+
+	return netip.Addr{}
+}
+
+// dhcpTag joins the parts with underscores and replaces all
+// characters that are not valid in tags with underscores.
+func dhcpTag(parts ...string) string {
+	return invalidDHCPTagChars.ReplaceAllString(strings.Join(parts, "_"), "_")
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"net/netip"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DHCPConfig", func() {
+	var repository *ConfigRepository
+
+	chainload := DHCPOptions{ScriptURL: "http://10.0.0.2:8080/v1beta1/boot/index.ipxe", TFTPServer: "10.0.0.2"}
+
+	BeforeEach(func() {
+		repository = loadExportRepository()
+	})
+
+	DescribeTable("should render the configuration",
+		func(format string, options DHCPOptions, golden string) {
+			data, err := repository.DHCPConfig(format, options)
+			Expect(err).NotTo(HaveOccurred())
+			expectGolden(golden, data)
+		},
+		Entry("for dnsmasq", "dnsmasq", DHCPOptions{}, "dnsmasq.conf"),
+		Entry("for dnsmasq with iPXE", "dnsmasq", chainload, "dnsmasq-ipxe.conf"),
+		Entry("for kea", "kea", DHCPOptions{}, "kea.json"),
+		Entry("for kea with iPXE", "kea", chainload, "kea-ipxe.json"),
+		Entry("for ethers", "ethers", chainload, "ethers"),
+	)
+
+	It("should reject an unsupported format", func() {
+		_, err := repository.DHCPConfig("isc", DHCPOptions{})
+		Expect(err).To(MatchError("unsupported format: isc"))
+	})
+
+	It("should reserve the first IPv4 address of every interface", func() {
+		hosts, err := repository.DHCPHosts()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hosts).To(HaveLen(4))
+
+		Expect(hosts[0].Hostname).To(Equal("ant"))
+		Expect(hosts[0].Addr).To(Equal(netip.MustParseAddr("10.0.0.10")))
+		Expect(hosts[0].Tags).To(Equal([]string{"pool_lab01"}))
+		Expect(hosts[1].Hostname).To(Equal("ant"))
+		Expect(hosts[1].Addr.IsValid()).To(BeFalse())
+		Expect(hosts[2].Hostname).To(Equal("bee"))
+		Expect(hosts[2].Addr).To(Equal(netip.MustParseAddr("10.0.0.11")))
+		Expect(hosts[2].Tags).To(Equal([]string{"pool_lab01", "pool_office"}))
+		Expect(hosts[3].Hostname).To(Equal("cow"))
+		Expect(hosts[3].Tags).To(BeEmpty())
+	})
+})
@@ -0,0 +1,281 @@
+package config
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"maps"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultKubernetesAPIPort is the port of the Kubernetes
+// API if the endpoint of a Region does not specify one.
+const DefaultKubernetesAPIPort = 6443
+
+// DNSOptions configure the zones that are generated from the inventory.
+type DNSOptions struct {
+	// Domain is the domain of the forward zone, e.g. "lab.example.com".
+	Domain string
+	// Nameserver is the authoritative nameserver of the zones. Names
+	// that are not fully qualified are relative to the domain.
+	// Defaults to "ns1" in the domain.
+	Nameserver string
+	// NameserverAddresses are the addresses of the nameserver if it is
+	// part of the domain. They are added as glue records to the forward
+	// zone and are required, unless the nameserver is a Machine.
+	NameserverAddresses []netip.Addr
+	// Hostmaster is the mailbox of the person responsible for the
+	// zones in the format of the SOA record. Defaults to "hostmaster"
+	// in the domain.
+	Hostmaster string
+	// TTL is the default TTL of the records in seconds.
+	TTL int
+	// SRV adds SRV records for the control planes of every Region,
+	// e.g. "_kubernetes._tcp.lab01", to the forward zone.
+	SRV bool
+}
+
+// DNSRecord is a resource record of a zone.
+type DNSRecord struct {
+	// Name is relative to the origin of the zone.
+	Name  string
+	Type  string
+	Value string
+}
+
+// DNSZone is a zone in the master file format of RFC 1035.
+type DNSZone struct {
+	// Origin is the fully qualified name of the zone.
+	Origin  string
+	Options DNSOptions
+	Records []DNSRecord
+	// Serial is the serial of the SOA record, see UpdateSerial.
+	Serial uint32
+}
+
+// DNSZones returns the forward zone of the domain and the reverse zones
+// of all static and observed addresses of Machines that are not decommissioned.
+// IPv4 addresses are grouped into /24 and IPv6 addresses into /64 zones.
+func (r *ConfigRepository) DNSZones(options DNSOptions) ([]*DNSZone, error) {
+	domain := strings.Trim(options.Domain, ".")
+	if domain == "" {
+		return nil, fmt.Errorf("domain is required")
+	}
+
+	if options.Nameserver == "" {
+		options.Nameserver = "ns1"
+	}
+
+	// The nameserver is used in the reverse zones,
+	// so it must not be relative to the origin.
+	if !strings.HasSuffix(options.Nameserver, ".") {
+		options.Nameserver += "." + domain + "."
+	}
+
+	if options.Hostmaster == "" {
+		options.Hostmaster = "hostmaster." + domain + "."
+	}
+
+	if options.TTL == 0 {
+		options.TTL = 3600
+	}
+
+	forward := &DNSZone{Origin: domain + ".", Options: options}
+	reverse := make(map[string]*DNSZone)
+	resolvable := make(map[string]bool)
+
+	for index := range r.Machines.Items {
+		machine := &r.Machines.Items[index]
+		if machine.Spec.Decommissioned {
+			continue
+		}
+
+		for _, addr := range MachineAddresses(machine) {
+			addr = addr.Unmap()
+			forward.Records = append(forward.Records, addressRecord(machine.Name, addr))
+			resolvable[machine.Name] = true
+
+			origin, name := reverseName(addr)
+			if reverse[origin] == nil {
+				reverse[origin] = &DNSZone{Origin: origin, Options: options}
+			}
+			reverse[origin].Records = append(reverse[origin].Records, DNSRecord{
+				Name:  name,
+				Type:  "PTR",
+				Value: machine.Name + "." + forward.Origin,
+			})
+		}
+	}
+
+	if name, ok := strings.CutSuffix(options.Nameserver, "."+forward.Origin); ok {
+		for _, addr := range options.NameserverAddresses {
+			forward.Records = append(forward.Records, addressRecord(name, addr.Unmap()))
+			resolvable[name] = true
+		}
+
+		if !resolvable[name] {
+			return nil, fmt.Errorf("nameserver %s is part of the domain and requires an address", options.Nameserver)
+		}
+	}
+
+	if options.SRV {
+		records, err := r.controlPlaneSRVRecords(forward.Origin, resolvable)
+		if err != nil {
+			return nil, err
+		}
+		forward.Records = append(forward.Records, records...)
+	}
+
+	zones := []*DNSZone{forward}
+	for _, origin := range slices.Sorted(maps.Keys(reverse)) {
+		zones = append(zones, reverse[origin])
+	}
+
+	for _, zone := range zones {
+		slices.SortFunc(zone.Records, func(a, b DNSRecord) int {
+			return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Type, b.Type), cmp.Compare(a.Value, b.Value))
+		})
+		zone.Records = slices.Compact(zone.Records)
+	}
+
+	return zones, nil
+}
+
+// addressRecord returns the A or AAAA record of an address.
+func addressRecord(name string, addr netip.Addr) DNSRecord {
+	if addr.Is4() {
+		return DNSRecord{Name: name, Type: "A", Value: addr.String()}
+	}
+
+	return DNSRecord{Name: name, Type: "AAAA", Value: addr.String()}
+}
+
+// controlPlaneSRVRecords returns a SRV record for every control plane of
+// every Region that points at the port of the endpoint. Control planes
+// without A or AAAA record in the forward zone, e.g. because they have no
+// address or are decommissioned, are skipped, as SRV targets must resolve.
+func (r *ConfigRepository) controlPlaneSRVRecords(origin string, resolvable map[string]bool) ([]DNSRecord, error) {
+	records := make([]DNSRecord, 0)
+	for _, region := range r.Regions.Items {
+		baremetal := region.Spec.Baremetal
+		if baremetal == nil {
+			continue
+		}
+
+		port := DefaultKubernetesAPIPort
+		if baremetal.Endpoint != "" {
+			endpoint, err := url.Parse(baremetal.Endpoint)
+			if err != nil {
+				return nil, fmt.Errorf("region %s has an invalid endpoint: %w", region.Name, err)
+			}
+
+			if endpoint.Port() != "" {
+				if port, err = strconv.Atoi(endpoint.Port()); err != nil {
+					return nil, fmt.Errorf("region %s has an invalid endpoint port: %w", region.Name, err)
+				}
+			}
+		}
+
+		for _, ref := range baremetal.Controlplanes {
+			if !resolvable[ref.Name] {
+				continue
+			}
+
+			records = append(records, DNSRecord{
+				Name:  "_kubernetes._tcp." + region.Name,
+				Type:  "SRV",
+				Value: fmt.Sprintf("0 100 %d %s.%s", port, ref.Name, origin),
+			})
+		}
+	}
+
+	return records, nil
+}
+
+// serialPattern matches the serial of a zone rendered by DNSZone.String.
+var serialPattern = regexp.MustCompile(`(?m)^\t\t(\d+)\t; serial$`)
+
+// UpdateSerial sets the serial of the zone based on the previously
+// generated zone file. The previous serial is kept if the content of the
+// zone did not change. Otherwise, the serial is set to the current Unix
+// time or, if the previous serial is not lower, to the previous serial
+// plus one, so that it increases monotonically as long as the previous
+// zone file is kept, e.g. in the repository. The serial is not
+// coordinated between multiple primaries that generate the zone.
+func (z *DNSZone) UpdateSerial(previous []byte, now time.Time) {
+	z.Serial = uint32(now.Unix())
+
+	match := serialPattern.FindSubmatch(previous)
+	if match == nil {
+		return
+	}
+
+	serial, err := strconv.ParseUint(string(match[1]), 10, 32)
+	if err != nil {
+		return
+	}
+
+	unchanged := *z
+	unchanged.Serial = uint32(serial)
+	if bytes.Equal([]byte(unchanged.String()), previous) {
+		z.Serial = unchanged.Serial
+		return
+	}
+
+	// Serials are compared with the arithmetic of RFC 1982.
+	if int32(z.Serial-unchanged.Serial) <= 0 {
+		z.Serial = unchanged.Serial + 1
+	}
+}
+
+// String renders the zone in the master file format of RFC 1035.
+func (z *DNSZone) String() string {
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, "$ORIGIN %s\n", z.Origin)
+	fmt.Fprintf(builder, "$TTL %d\n", z.Options.TTL)
+	fmt.Fprintf(builder, "@\tIN\tSOA\t%s %s (\n", z.Options.Nameserver, z.Options.Hostmaster)
+	fmt.Fprintf(builder, "\t\t%d\t; serial\n", z.Serial)
+	builder.WriteString("\t\t3600\t; refresh\n")
+	builder.WriteString("\t\t900\t; retry\n")
+	builder.WriteString("\t\t604800\t; expire\n")
+	builder.WriteString("\t\t300 )\t; minimum\n")
+	fmt.Fprintf(builder, "@\tIN\tNS\t%s\n", z.Options.Nameserver)
+	builder.WriteString(z.records())
+
+	return builder.String()
+}
+
+// records renders the records of the zone.
+func (z *DNSZone) records() string {
+	builder := &strings.Builder{}
+	for _, record := range z.Records {
+		fmt.Fprintf(builder, "%s\tIN\t%s\t%s\n", record.Name, record.Type, record.Value)
+	}
+
+	return builder.String()
+}
+
+// reverseName returns the reverse zone of an address and the name
+// of its PTR record relative to the zone. IPv4 zones contain a /24
+// and IPv6 zones a /64 as defined in RFC 1035 and RFC 3596.
+func reverseName(addr netip.Addr) (string, string) {
+	if addr.Is4() {
+		octets := addr.As4()
+		return fmt.Sprintf("%d.%d.%d.in-addr.arpa.", octets[2], octets[1], octets[0]), strconv.Itoa(int(octets[3]))
+	}
+
+	octets := addr.As16()
+	nibbles := make([]string, 0, 32)
+	for index := len(octets) - 1; index >= 0; index-- {
+		nibbles = append(nibbles, strconv.FormatUint(uint64(octets[index]&0x0f), 16), strconv.FormatUint(uint64(octets[index]>>4), 16))
+	}
+
+	return strings.Join(nibbles[16:], ".") + ".ip6.arpa.", strings.Join(nibbles[:16], ".")
+}
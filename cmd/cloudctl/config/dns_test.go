@@ -0,0 +1,95 @@
+package config
+
+import (
+	"net/netip"
+	"path"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DNSZones", func() {
+	var repository *ConfigRepository
+
+	BeforeEach(func() {
+		var err error
+		repository, err = LoadConfigRepository(path.Join("testdata", "dns"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should add glue records for the nameserver", func() {
+		zones, err := repository.DNSZones(DNSOptions{
+			Domain:              "lab.example.com",
+			NameserverAddresses: []netip.Addr{netip.MustParseAddr("10.0.0.53"), netip.MustParseAddr("fd00::53")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(zones).To(HaveLen(2))
+		Expect(zones[0].Options.Nameserver).To(Equal("ns1.lab.example.com."))
+		Expect(zones[0].Records).To(Equal([]DNSRecord{
+			{Name: "ant", Type: "A", Value: "10.0.0.10"},
+			{Name: "ns1", Type: "A", Value: "10.0.0.53"},
+			{Name: "ns1", Type: "AAAA", Value: "fd00::53"},
+		}))
+		Expect(zones[1].Origin).To(Equal("0.0.10.in-addr.arpa."))
+		Expect(zones[1].Records).To(Equal([]DNSRecord{
+			{Name: "10", Type: "PTR", Value: "ant.lab.example.com."},
+		}))
+	})
+
+	It("should require an address for a nameserver in the domain", func() {
+		_, err := repository.DNSZones(DNSOptions{Domain: "lab.example.com"})
+		Expect(err).To(MatchError(ContainSubstring("nameserver ns1.lab.example.com. is part of the domain")))
+	})
+
+	It("should accept a Machine or a nameserver outside of the domain", func() {
+		_, err := repository.DNSZones(DNSOptions{Domain: "lab.example.com", Nameserver: "ant"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = repository.DNSZones(DNSOptions{Domain: "lab.example.com", Nameserver: "ns1.example.org."})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should only point SRV records at control planes with an address", func() {
+		zones, err := repository.DNSZones(DNSOptions{Domain: "lab.example.com", Nameserver: "ant", SRV: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(zones[0].Records).To(ContainElement(DNSRecord{
+			Name:  "_kubernetes._tcp.lab01",
+			Type:  "SRV",
+			Value: "0 100 6443 ant.lab.example.com.",
+		}))
+		Expect(zones[0].Records).NotTo(ContainElement(HaveField("Value", ContainSubstring("bee"))))
+		Expect(zones[0].Records).NotTo(ContainElement(HaveField("Value", ContainSubstring("cow"))))
+	})
+})
+
+var _ = Describe("DNSZone", func() {
+	now := time.Unix(1700000000, 0)
+
+	zone := func(value string) *DNSZone {
+		return &DNSZone{
+			Origin:  "lab.example.com.",
+			Options: DNSOptions{Nameserver: "ant.lab.example.com.", Hostmaster: "hostmaster.lab.example.com.", TTL: 3600},
+			Records: []DNSRecord{{Name: "ant", Type: "A", Value: value}},
+		}
+	}
+
+	previous := func(serial uint32) []byte {
+		previous := zone("10.0.0.10")
+		previous.Serial = serial
+		return []byte(previous.String())
+	}
+
+	DescribeTable("should increase the serial monotonically",
+		func(value string, previous []byte, serial uint32) {
+			zone := zone(value)
+			zone.UpdateSerial(previous, now)
+			Expect(zone.Serial).To(Equal(serial))
+		},
+		Entry("without previous zone", "10.0.0.10", nil, uint32(1700000000)),
+		Entry("with unchanged content", "10.0.0.10", previous(1800000000), uint32(1800000000)),
+		Entry("with a lower previous serial", "10.0.0.11", previous(1600000000), uint32(1700000000)),
+		Entry("with a higher previous serial", "10.0.0.11", previous(1800000000), uint32(1800000001)),
+		Entry("with a previous serial before the wrap-around", "10.0.0.11", previous(4294967295), uint32(1700000000)),
+	)
+})
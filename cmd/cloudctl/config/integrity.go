@@ -0,0 +1,431 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+)
+
+// CheckIntegrity checks the references and the uniqueness constraints
+// between the resources of the configuration repository. In contrast to
+// the OpenAPI schema, these checks require knowledge of all resources or,
+// like overlapping networks, can not be expressed by the schema.
+// The findings are sorted by file.
+func (r *ConfigRepository) CheckIntegrity() []Finding {
+	findings := make([]Finding, 0)
+
+	findings = append(findings, r.checkDuplicateNames()...)
+	findings = append(findings, r.checkRegionReferences()...)
+	findings = append(findings, r.checkHardwareProfileReferences()...)
+	findings = append(findings, r.checkDuplicateMACs()...)
+	findings = append(findings, r.checkDuplicateDisks()...)
+	findings = append(findings, r.checkNetworks()...)
+	findings = append(findings, r.checkDuplicateAddresses()...)
+	findings = append(findings, r.checkPoolMembership()...)
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].File < findings[j].File
+	})
+
+	return findings
+}
+
+// finding returns an error finding for the given resource.
+func (r *ConfigRepository) finding(rule string, kind string, name string, path *field.Path, format string, args ...any) Finding {
+	finding := Finding{
+		File:     r.Source(kind, name),
+		Kind:     kind,
+		Name:     name,
+		Severity: SeverityError,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if path != nil {
+		finding.Field = path.String()
+	}
+
+	return finding
+}
+
+// checkDuplicateNames reports resources of the same kind that share a name.
+func (r *ConfigRepository) checkDuplicateNames() []Finding {
+	findings := make([]Finding, 0)
+
+	for key, files := range r.Sources {
+		// Every file after the first one is reported as a duplicate.
+		for _, file := range files[1:] {
+			findings = append(findings, Finding{
+				File:     file,
+				Kind:     key.Kind,
+				Name:     key.Name,
+				Severity: SeverityError,
+				Rule:     RuleDuplicateName,
+				Field:    "metadata.name",
+				Message:  fmt.Sprintf("duplicate name, already defined in %s", files[0]),
+			})
+		}
+	}
+
+	return findings
+}
+
+// firstOccurrences returns the resources whose name was not seen before. The
+// other checks ignore duplicates as they would be ambiguous, the duplicates
+// themselves are already reported by checkDuplicateNames.
+func firstOccurrences[T any, PT interface {
+	*T
+	CRD
+}](items []T) []PT {
+	seen := make(map[string]bool, len(items))
+	resources := make([]PT, 0, len(items))
+	for index := range items {
+		resource := PT(&items[index])
+
+		name := resource.GetObjectMeta().GetName()
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		resources = append(resources, resource)
+	}
+
+	return resources
+}
+
+// checkRegionReferences reports Regions that reference unknown Machines
+// and Machines that are referenced more than once by any Region.
+func (r *ConfigRepository) checkRegionReferences() []Finding {
+	findings := make([]Finding, 0)
+
+	machines := make(map[string]bool, len(r.Machines.Items))
+	for _, machine := range r.Machines.Items {
+		machines[machine.Name] = true
+	}
+
+	owners := make(map[string]string)
+	for _, region := range firstOccurrences(r.Regions.Items) {
+		if region.Spec.Baremetal == nil {
+			continue
+		}
+
+		baremetal := field.NewPath("spec", "baremetal")
+		roles := []struct {
+			path *field.Path
+			refs []cloud.MachineReference
+		}{
+			{path: baremetal.Child("controlplanes"), refs: region.Spec.Baremetal.Controlplanes},
+			{path: baremetal.Child("workers"), refs: region.Spec.Baremetal.Workers},
+		}
+
+		for _, role := range roles {
+			for index, ref := range role.refs {
+				path := role.path.Index(index).Child("name")
+
+				if !machines[ref.Name] {
+					findings = append(findings, r.finding(RuleUnknownReference, "Region", region.Name, path,
+						"references unknown Machine %s", ref.Name))
+				}
+
+				if owner, ok := owners[ref.Name]; ok {
+					findings = append(findings, r.finding(RuleDuplicateReference, "Region", region.Name, path,
+						"Machine %s is already referenced by Region %s", ref.Name, owner))
+					continue
+				}
+
+				owners[ref.Name] = region.Name
+			}
+		}
+	}
+
+	return findings
+}
+
+// checkHardwareProfileReferences reports Machines that reference unknown HardwareProfiles.
+func (r *ConfigRepository) checkHardwareProfileReferences() []Finding {
+	findings := make([]Finding, 0)
+
+	profiles := make(map[string]bool, len(r.HardwareProfiles.Items))
+	for _, profile := range r.HardwareProfiles.Items {
+		profiles[profile.Name] = true
+	}
+
+	for _, machine := range firstOccurrences(r.Machines.Items) {
+		ref := machine.Spec.Hardware.ProfileRef
+		if ref != nil && !profiles[ref.Name] {
+			findings = append(findings, r.finding(RuleUnknownReference, "Machine", machine.Name, field.NewPath("spec", "hardware", "profileRef", "name"),
+				"references unknown HardwareProfile %s", ref.Name))
+		}
+	}
+
+	return findings
+}
+
+// checkDuplicateMACs reports MAC addresses that are used by more than one interface.
+func (r *ConfigRepository) checkDuplicateMACs() []Finding {
+	findings := make([]Finding, 0)
+
+	owners := make(map[string]string)
+	for _, machine := range firstOccurrences(r.Machines.Items) {
+		interfaces := field.NewPath("spec", "interfaces")
+		for index, iface := range machine.Spec.Interfaces {
+			mac := iface.MAC.String()
+
+			if owner, ok := owners[mac]; ok {
+				findings = append(findings, r.finding(RuleDuplicateMAC, "Machine", machine.Name, interfaces.Index(index).Child("mac"),
+					"duplicate MAC %s, already used by Machine %s", mac, owner))
+				continue
+			}
+
+			owners[mac] = machine.Name
+		}
+	}
+
+	return findings
+}
+
+// checkDuplicateDisks reports disks whose path or serial number is used by more
+// than one disk, as a disk can only be installed in a single Machine at a time.
+func (r *ConfigRepository) checkDuplicateDisks() []Finding {
+	findings := make([]Finding, 0)
+
+	owners := make(map[string]string)
+	for _, machine := range firstOccurrences(r.Machines.Items) {
+		disks := field.NewPath("spec", "disks")
+		for index, disk := range machine.Spec.Disks {
+			identifiers := []struct {
+				path  *field.Path
+				kind  string
+				value string
+			}{
+				{path: disks.Index(index).Child("byID"), kind: "path", value: disk.ByID},
+				{path: disks.Index(index).Child("serial"), kind: "serial", value: disk.Serial},
+			}
+
+			for _, identifier := range identifiers {
+				if identifier.value == "" {
+					continue
+				}
+
+				key := identifier.kind + "/" + identifier.value
+				if owner, ok := owners[key]; ok {
+					findings = append(findings, r.finding(RuleDuplicateDisk, "Machine", machine.Name, identifier.path,
+						"duplicate disk %s %s, already used by Machine %s", identifier.kind, identifier.value, owner))
+					continue
+				}
+
+				owners[key] = machine.Name
+			}
+		}
+	}
+
+	return findings
+}
+
+// link is a network link of a Machine with the path of its configuration.
+type link struct {
+	name   string
+	path   *field.Path
+	config cloud.LinkConfig
+	// parent is the name of the link that a VLAN is on top of.
+	parent string
+	// vlans is the number of VLANs on top of the link.
+	vlans int
+}
+
+// machineLinks returns the interfaces, bonds and bridges of a Machine
+// followed by their VLANs. Unnamed interfaces are named by their MAC.
+func machineLinks(machine *cloud.Machine) []link {
+	links := make([]link, 0)
+	add := func(name string, path *field.Path, config cloud.LinkConfig, vlans []cloud.VLAN) {
+		links = append(links, link{name: name, path: path, config: config, vlans: len(vlans)})
+		for index, vlan := range vlans {
+			links = append(links, link{
+				name:   fmt.Sprintf("%s.%d", name, vlan.ID),
+				path:   path.Child("vlans").Index(index),
+				config: vlan.LinkConfig,
+				parent: name,
+			})
+		}
+	}
+
+	spec := field.NewPath("spec")
+	for index, iface := range machine.Spec.Interfaces {
+		name := iface.Name
+		if name == "" {
+			name = iface.MAC.String()
+		}
+		add(name, spec.Child("interfaces").Index(index), iface.LinkConfig, iface.VLANs)
+	}
+	for index, bond := range machine.Spec.Bonds {
+		add(bond.Name, spec.Child("bonds").Index(index), bond.LinkConfig, bond.VLANs)
+	}
+	for index, bridge := range machine.Spec.Bridges {
+		add(bridge.Name, spec.Child("bridges").Index(index), bridge.LinkConfig, bridge.VLANs)
+	}
+
+	return links
+}
+
+// checkNetworks reports Machines with duplicate link names, links that are
+// members of more than one bond or bridge or that are configured although
+// they are members, gateways outside of the networks of their link and
+// addresses whose networks overlap with the networks of other links.
+func (r *ConfigRepository) checkNetworks() []Finding {
+	findings := make([]Finding, 0)
+
+	for _, machine := range firstOccurrences(r.Machines.Items) {
+		links := machineLinks(machine)
+
+		// VLANs can not be members of bonds or bridges and their IDs are
+		// unique per link, so only the names of the other links are checked.
+		configs := make(map[string]link)
+		for _, link := range links {
+			if link.parent != "" {
+				continue
+			}
+
+			if _, ok := configs[link.name]; ok {
+				findings = append(findings, r.finding(RuleInvalidNetwork, "Machine", machine.Name, link.path.Child("name"),
+					"duplicate link name %s", link.name))
+				continue
+			}
+
+			configs[link.name] = link
+		}
+
+		masters := make(map[string]string)
+		members := func(path *field.Path, master string, interfaces []string) {
+			for index, name := range interfaces {
+				if owner, ok := masters[name]; ok {
+					findings = append(findings, r.finding(RuleInvalidNetwork, "Machine", machine.Name, path.Child("interfaces").Index(index),
+						"link %s is already a member of %s", name, owner))
+					continue
+				}
+				masters[name] = master
+
+				member, ok := configs[name]
+				if ok && (len(member.config.Addresses) > 0 || member.config.Gateway != "" || member.vlans > 0) {
+					findings = append(findings, r.finding(RuleInvalidNetwork, "Machine", machine.Name, member.path,
+						"link %s must not have addresses, a gateway or VLANs as it is a member of %s", name, master))
+				}
+			}
+		}
+		for index, bond := range machine.Spec.Bonds {
+			members(field.NewPath("spec", "bonds").Index(index), bond.Name, bond.Interfaces)
+		}
+		for index, bridge := range machine.Spec.Bridges {
+			members(field.NewPath("spec", "bridges").Index(index), bridge.Name, bridge.Interfaces)
+		}
+
+		type owner struct {
+			prefix netip.Prefix
+			link   int
+		}
+		owners := make([]owner, 0)
+		for current, link := range links {
+			prefixes := link.config.Prefixes()
+
+			if link.config.Gateway != "" {
+				gateway, err := netip.ParseAddr(link.config.Gateway)
+				if err == nil && !slices.ContainsFunc(prefixes, func(prefix netip.Prefix) bool { return prefix.Contains(gateway) }) {
+					findings = append(findings, r.finding(RuleInvalidNetwork, "Machine", machine.Name, link.path.Child("gateway"),
+						"gateway %s is not part of the network of any address of link %s", gateway, link.name))
+				}
+			}
+
+			for index, prefix := range prefixes {
+				path := link.path.Child("addresses").Index(index)
+				for _, other := range owners {
+					if other.prefix.Addr() == prefix.Addr() {
+						findings = append(findings, r.finding(RuleInvalidNetwork, "Machine", machine.Name, path,
+							"duplicate address %s, already used by link %s", prefix.Addr(), links[other.link].name))
+						break
+					}
+					if other.link != current && other.prefix.Overlaps(prefix) {
+						findings = append(findings, r.finding(RuleInvalidNetwork, "Machine", machine.Name, path,
+							"network of address %s overlaps with address %s of link %s", prefix, other.prefix, links[other.link].name))
+						break
+					}
+				}
+				owners = append(owners, owner{prefix: prefix, link: current})
+			}
+		}
+	}
+
+	return findings
+}
+
+// checkDuplicateAddresses reports static addresses that are used by more than
+// one Machine. Duplicates within a Machine are reported by checkNetworks.
+func (r *ConfigRepository) checkDuplicateAddresses() []Finding {
+	findings := make([]Finding, 0)
+
+	owners := make(map[netip.Addr]string)
+	for _, machine := range firstOccurrences(r.Machines.Items) {
+		for _, link := range machineLinks(machine) {
+			for index, prefix := range link.config.Prefixes() {
+				addr := prefix.Addr()
+				if owner, ok := owners[addr]; ok && owner != machine.Name {
+					findings = append(findings, r.finding(RuleDuplicateAddress, "Machine", machine.Name, link.path.Child("addresses").Index(index),
+						"duplicate address %s, already used by Machine %s", addr, owner))
+					continue
+				}
+
+				owners[addr] = machine.Name
+			}
+		}
+	}
+
+	return findings
+}
+
+// checkPoolMembership reports MachinePools with invalid or identical
+// selectors and Machines that are matched by more than one MachinePool.
+func (r *ConfigRepository) checkPoolMembership() []Finding {
+	findings := make([]Finding, 0)
+
+	machines := firstOccurrences(r.Machines.Items)
+
+	selectors := make(map[string]string)
+	memberships := make(map[string][]string)
+	for _, pool := range firstOccurrences(r.MachinePools.Items) {
+		selector, err := pool.Spec.Selector.AsSelector()
+		if err != nil {
+			findings = append(findings, r.finding(RuleInvalidSelector, "MachinePool", pool.Name, field.NewPath("spec", "selector"), "%v", err))
+			continue
+		}
+
+		// Selectors are normalized by their string representation, which
+		// sorts the requirements, so that identical selectors are detected
+		// even if they are written differently.
+		if owner, ok := selectors[selector.String()]; ok {
+			findings = append(findings, r.finding(RuleOverlappingPools, "MachinePool", pool.Name, field.NewPath("spec", "selector"),
+				"selector overlaps with MachinePool %s", owner))
+		} else {
+			selectors[selector.String()] = pool.Name
+		}
+
+		for _, machine := range machines {
+			if selector.Matches(labels.Set(machine.Labels)) {
+				memberships[machine.Name] = append(memberships[machine.Name], pool.Name)
+			}
+		}
+	}
+
+	for _, machine := range machines {
+		if pools := memberships[machine.Name]; len(pools) > 1 {
+			findings = append(findings, r.finding(RuleOverlappingPools, "Machine", machine.Name, field.NewPath("metadata", "labels"),
+				"matched by more than one MachinePool: %s", strings.Join(pools, ", ")))
+		}
+	}
+
+	return findings
+}
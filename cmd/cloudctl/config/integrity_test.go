@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// describeFindings returns the rule, the file relative to
+// the repository and the field of every finding.
+func describeFindings(dir string, findings []Finding) []string {
+	lines := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		file := strings.TrimPrefix(finding.File, dir+"/")
+		lines = append(lines, fmt.Sprintf("%s %s %s", finding.Rule, file, finding.Field))
+	}
+
+	return lines
+}
+
+var _ = Describe("CheckIntegrity", func() {
+	DescribeTable("should report the violations of every rule",
+		func(fixture string, expected ...string) {
+			dir := path.Join("testdata", "integrity", fixture)
+
+			repository, err := LoadConfigRepository(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(describeFindings(dir, repository.CheckIntegrity())).To(ConsistOf(expected))
+		},
+		Entry("valid repository", "valid"),
+		Entry("duplicate name", "duplicate-name",
+			"duplicate-name machines/ant.yaml metadata.name"),
+		Entry("unknown reference", "unknown-reference",
+			"unknown-reference machines/ant.yaml spec.hardware.profileRef.name",
+			"unknown-reference regions/lab01.yaml spec.baremetal.controlplanes[1].name"),
+		Entry("duplicate reference", "duplicate-reference",
+			"duplicate-reference regions/lab02.yaml spec.baremetal.controlplanes[0].name"),
+		Entry("duplicate MAC", "duplicate-mac",
+			"duplicate-mac machines/bee.yaml spec.interfaces[0].mac"),
+		Entry("duplicate disk", "duplicate-disk",
+			"duplicate-disk machines/bee.yaml spec.disks[0].serial"),
+		Entry("duplicate address", "duplicate-address",
+			"duplicate-address machines/bee.yaml spec.interfaces[0].addresses[0]"),
+		Entry("duplicate link name", "duplicate-link-name",
+			"invalid-network machines/ant.yaml spec.bonds[0].name"),
+		Entry("gateway outside of the networks of the link", "gateway-outside-prefix",
+			"invalid-network machines/ant.yaml spec.interfaces[0].gateway"),
+		Entry("configured member and member of two links", "configured-member",
+			"invalid-network machines/ant.yaml spec.interfaces[0]",
+			"invalid-network machines/ant.yaml spec.bridges[0].interfaces[0]"),
+		Entry("duplicate address and overlapping networks", "overlapping-networks",
+			"invalid-network machines/ant.yaml spec.interfaces[0].addresses[1]",
+			"invalid-network machines/ant.yaml spec.interfaces[1].addresses[0]"),
+		Entry("overlapping pools", "overlapping-pools",
+			"overlapping-pools machinepools/lab02.yaml spec.selector",
+			"overlapping-pools machines/ant.yaml metadata.labels"),
+		Entry("invalid selector", "invalid-selector",
+			"invalid-selector machinepools/lab01.yaml spec.selector"),
+	)
+
+	It("should check the manifests that can be loaded", func() {
+		dir := path.Join("testdata", "partial")
+
+		_, err := LoadConfigRepository(dir)
+		Expect(err).To(HaveOccurred())
+
+		repository, failed, err := LoadPartialConfigRepository(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failed).To(HaveLen(1))
+		Expect(failed[0].File).To(Equal(path.Join(dir, "machines", "cow.yaml")))
+		Expect(describeFindings(dir, repository.CheckIntegrity())).To(ConsistOf(
+			"duplicate-mac machines/bee.yaml spec.interfaces[0].mac",
+		))
+	})
+})
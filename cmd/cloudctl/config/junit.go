@@ -0,0 +1,110 @@
+package config
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// The types below implement the JUnit XML format as understood by
+// most CI systems. Every directory is a test suite and every file
+// is a test case that fails if it has findings with severity error.
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes the report as JUnit XML.
+func (r *ValidationReport) writeJUnit(w io.Writer) error {
+	suites := junitTestSuites{
+		Name: "labctl config validate",
+	}
+
+	index := make(map[string]int)
+	for _, result := range r.Results() {
+		suiteName := filepath.Dir(result.File)
+
+		suiteIndex, ok := index[suiteName]
+		if !ok {
+			suiteIndex = len(suites.TestSuites)
+			index[suiteName] = suiteIndex
+			suites.TestSuites = append(suites.TestSuites, junitTestSuite{Name: suiteName})
+		}
+		suite := &suites.TestSuites[suiteIndex]
+
+		testCase := junitTestCase{
+			Name:      result.File,
+			ClassName: result.Kind,
+		}
+
+		errors := make([]string, 0)
+		warnings := make([]string, 0)
+		for _, finding := range result.Findings {
+			message := finding.Message
+			if finding.Field != "" {
+				message = fmt.Sprintf("%s: %s", finding.Field, message)
+			}
+
+			if finding.Severity == SeverityError {
+				errors = append(errors, message)
+			} else {
+				warnings = append(warnings, message)
+			}
+		}
+
+		if len(errors) > 0 {
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d errors", len(errors)),
+				Type:    string(SeverityError),
+				Text:    strings.Join(errors, "\n"),
+			}
+			suite.Failures++
+			suites.Failures++
+		}
+		testCase.SystemOut = strings.Join(warnings, "\n")
+
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+		suites.Tests++
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write XML header: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+	"sigs.k8s.io/yaml"
+)
+
+// OpenAPIValidator validates manifests against the OpenAPI schemas
+// of the generated CustomResourceDefinitions, just like the
+// Kubernetes API server would do it when creating a resource.
+type OpenAPIValidator struct {
+	schemas map[schema.GroupVersionKind]*openAPISchema
+}
+
+// openAPISchema contains the validators of a single version of a CRD.
+type openAPISchema struct {
+	structural *structuralschema.Structural
+	validator  validation.SchemaValidator
+	cel        *cel.Validator
+}
+
+// NewOpenAPIValidator creates a validator from the CustomResourceDefinitions
+// stored as YAML files in the root of the given file system.
+func NewOpenAPIValidator(crds fs.FS) (*OpenAPIValidator, error) {
+	files, err := fs.Glob(crds, "*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CRDs: %w", err)
+	}
+
+	v := &OpenAPIValidator{
+		schemas: make(map[schema.GroupVersionKind]*openAPISchema),
+	}
+
+	for _, file := range files {
+		data, err := fs.ReadFile(crds, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CRD: %w", err)
+		}
+
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := yaml.Unmarshal(data, crd); err != nil {
+			return nil, fmt.Errorf("failed to decode CRD %s: %w", file, err)
+		}
+
+		for _, version := range crd.Spec.Versions {
+			if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+
+			gvk := schema.GroupVersionKind{
+				Group:   crd.Spec.Group,
+				Version: version.Name,
+				Kind:    crd.Spec.Names.Kind,
+			}
+
+			s, err := newOpenAPISchema(version.Schema.OpenAPIV3Schema)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load schema of %s: %w", gvk, err)
+			}
+
+			v.schemas[gvk] = s
+		}
+	}
+
+	return v, nil
+}
+
+// newOpenAPISchema converts the schema of a CRD version into validators.
+func newOpenAPISchema(versionSchema *apiextensionsv1.JSONSchemaProps) (*openAPISchema, error) {
+	internalSchema := &apiextensions.JSONSchemaProps{}
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(versionSchema, internalSchema, nil); err != nil {
+		return nil, fmt.Errorf("failed to convert schema: %w", err)
+	}
+
+	structural, err := structuralschema.NewStructural(internalSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create structural schema: %w", err)
+	}
+
+	validator, _, err := validation.NewSchemaValidator(internalSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema validator: %w", err)
+	}
+
+	return &openAPISchema{
+		structural: structural,
+		validator:  validator,
+		cel:        cel.NewValidator(structural, true, celconfig.PerCallLimit),
+	}, nil
+}
+
+// Validate validates a resource against the schema of its kind.
+// Every violation is reported with the path of the offending field.
+func (v *OpenAPIValidator) Validate(obj *unstructured.Unstructured) field.ErrorList {
+	gvk := obj.GroupVersionKind()
+
+	s, ok := v.schemas[gvk]
+	if !ok {
+		return field.ErrorList{
+			field.NotSupported(field.NewPath("kind"), gvk.Kind, v.kinds()),
+		}
+	}
+
+	allErrs := field.ErrorList{}
+
+	name := obj.GetName()
+	if name == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("metadata", "name"), ""))
+	}
+	for _, msg := range utilvalidation.IsDNS1123Subdomain(name) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), name, msg))
+	}
+
+	// The pruning algorithm modifies the object, so we work on a copy.
+	content := runtime.DeepCopyJSON(obj.UnstructuredContent())
+
+	unknownFields := pruning.PruneWithOptions(content, s.structural, true, structuralschema.UnknownFieldPathOptions{
+		TrackUnknownFieldPaths: true,
+	})
+	for _, unknownField := range unknownFields {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath(unknownField), "unknown field"))
+	}
+
+	allErrs = append(allErrs, validation.ValidateCustomResource(nil, content, s.validator)...)
+
+	celErrs, _ := s.cel.Validate(context.Background(), nil, s.structural, content, nil, celconfig.RuntimeCELCostBudget)
+	allErrs = append(allErrs, celErrs...)
+
+	return allErrs
+}
+
+// kinds returns the kinds known to the validator.
+func (v *OpenAPIValidator) kinds() []string {
+	kinds := make([]string, 0, len(v.schemas))
+	for gvk := range v.schemas {
+		kinds = append(kinds, gvk.Kind)
+	}
+	sort.Strings(kinds)
+
+	return kinds
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+)
+
+// PrometheusNodeExporterPort is the default port of the node exporter.
+const PrometheusNodeExporterPort = 9100
+
+// Files of the Prometheus targets in the rendered artifacts.
+const (
+	PrometheusNodeFile     = "prometheus/node.json"
+	PrometheusBlackboxFile = "prometheus/blackbox.json"
+)
+
+// invalidPrometheusLabelChars matches the characters that Prometheus does not allow in label names.
+var invalidPrometheusLabelChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// PrometheusTargetGroup is a target group in the format of file_sd_configs.
+// Reference: https://prometheus.io/docs/prometheus/latest/configuration/configuration/#file_sd_config
+type PrometheusTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// PrometheusTargets returns a target group for every MachinePool with the
+// Machines of the pool that are not decommissioned. A Machine that is part
+// of more than one pool is only added to the first pool by name, so that
+// it is not scraped twice. Machines that are not part of any pool are
+// returned in a final group without pool label. The
+// target of a Machine is its first static or observed address or its name
+// and the port, unless the port is 0, which is useful for blackbox probes.
+// Labels that all Machines of a group share are added to the group, e.g.
+// vendor and model of the hardware or the label
+// "topology.kubernetes.io/zone", which becomes
+// "label_topology_kubernetes_io_zone".
+// The hardware profiles must be resolved before.
+func (r *ConfigRepository) PrometheusTargets(port int) ([]PrometheusTargetGroup, error) {
+	groups := make([]PrometheusTargetGroup, 0, len(r.MachinePools.Items)+1)
+	pooled := make(map[string]bool, len(r.Machines.Items))
+
+	pools := ToPointerSlice(r.MachinePools.Items)
+	slices.SortFunc(pools, func(a, b *cloud.MachinePool) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	for _, pool := range pools {
+		machines, err := r.MachinesForPool(pool)
+		if err != nil {
+			return nil, err
+		}
+
+		// A Machine in more than one pool is only scraped once with the
+		// first pool by name. Overlapping pools are reported by validate.
+		machines = slices.DeleteFunc(machines, func(machine *cloud.Machine) bool {
+			return pooled[machine.Name]
+		})
+		for _, machine := range machines {
+			pooled[machine.Name] = true
+		}
+
+		if group, ok := prometheusTargetGroup(machines, port); ok {
+			group.Labels["pool"] = pool.Name
+			groups = append(groups, group)
+		}
+	}
+
+	unpooled := make([]*cloud.Machine, 0)
+	for index := range r.Machines.Items {
+		if machine := &r.Machines.Items[index]; !pooled[machine.Name] {
+			unpooled = append(unpooled, machine)
+		}
+	}
+
+	if group, ok := prometheusTargetGroup(unpooled, port); ok {
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// prometheusTargetGroup returns the target group of the Machines that are
+// not decommissioned with the labels that all of them share. If none of the
+// Machines remains, no group is returned.
+func prometheusTargetGroup(machines []*cloud.Machine, port int) (PrometheusTargetGroup, bool) {
+	group := PrometheusTargetGroup{
+		Targets: make([]string, 0, len(machines)),
+	}
+
+	for _, machine := range machines {
+		if machine.Spec.Decommissioned {
+			continue
+		}
+
+		labels := prometheusLabels(machine)
+		if group.Labels == nil {
+			group.Labels = labels
+		} else {
+			maps.DeleteFunc(group.Labels, func(key string, value string) bool {
+				return labels[key] != value
+			})
+		}
+
+		group.Targets = append(group.Targets, prometheusTarget(machine, port))
+	}
+
+	if len(group.Targets) == 0 {
+		return group, false
+	}
+
+	slices.Sort(group.Targets)
+
+	return group, true
+}
+
+// prometheusLabels returns the labels that are derived from a Machine.
+func prometheusLabels(machine *cloud.Machine) map[string]string {
+	labels := make(map[string]string, len(machine.Labels)+2)
+	for key, value := range machine.Labels {
+		labels["label_"+invalidPrometheusLabelChars.ReplaceAllString(key, "_")] = value
+	}
+
+	if vendor := machine.Spec.Hardware.Vendor; vendor != "" {
+		labels["vendor"] = vendor
+	}
+
+	if model := machine.Spec.Hardware.Model; model != "" {
+		labels["model"] = model
+	}
+
+	return labels
+}
+
+// prometheusTarget returns the address of the Machine with
+// the port. The name is used if the Machine has no address.
+func prometheusTarget(machine *cloud.Machine, port int) string {
+	host := machine.Name
+	if addresses := MachineAddresses(machine); len(addresses) > 0 {
+		host = addresses[0].String()
+	}
+
+	if port == 0 {
+		return host
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// renderPrometheus renders the targets of the node exporter and
+// of blackbox probes, so that Prometheus can use them with file_sd_configs.
+func (r *ConfigRepository) renderPrometheus(artifacts Artifacts) error {
+	ports := map[string]int{
+		PrometheusNodeFile:     PrometheusNodeExporterPort,
+		PrometheusBlackboxFile: 0,
+	}
+
+	for file, port := range ports {
+		groups, err := r.PrometheusTargets(port)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(groups, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", path.Base(file), err)
+		}
+
+		artifacts[file] = append(data, '\n')
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PrometheusTargets", func() {
+	var repository *ConfigRepository
+
+	BeforeEach(func() {
+		repository = loadExportRepository()
+	})
+
+	DescribeTable("should render the targets",
+		func(port int, golden string) {
+			groups, err := repository.PrometheusTargets(port)
+			Expect(err).NotTo(HaveOccurred())
+
+			data, err := json.MarshalIndent(groups, "", "  ")
+			Expect(err).NotTo(HaveOccurred())
+			expectGolden(golden, append(data, '\n'))
+		},
+		Entry("of the node exporter", PrometheusNodeExporterPort, "prometheus-node.json"),
+		Entry("of blackbox probes", 0, "prometheus-blackbox.json"),
+	)
+
+	It("should group the machines by pool with the labels they share", func() {
+		groups, err := repository.PrometheusTargets(PrometheusNodeExporterPort)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(groups).To(Equal([]PrometheusTargetGroup{
+			{
+				Targets: []string{"10.0.0.10:9100", "[fd00::11]:9100"},
+				Labels:  map[string]string{"pool": "lab01", "label_pool": "lab01", "vendor": "FriendlyElec", "model": "NanoPiR5S"},
+			},
+			{
+				Targets: []string{"cow:9100"},
+				Labels:  map[string]string{"label_topology_kubernetes_io_zone": "home", "vendor": "Intel", "model": "NUC"},
+			},
+		}))
+	})
+
+	It("should only add a machine to the first of its pools", func() {
+		groups, err := repository.PrometheusTargets(0)
+		Expect(err).NotTo(HaveOccurred())
+
+		targets := make([]string, 0)
+		for _, group := range groups {
+			targets = append(targets, group.Targets...)
+		}
+		Expect(targets).To(ConsistOf("10.0.0.10", "fd00::11", "cow"))
+		Expect(groups).NotTo(ContainElement(HaveField("Labels", HaveKeyWithValue("pool", "office"))))
+	})
+})
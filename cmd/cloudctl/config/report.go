@@ -0,0 +1,234 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+)
+
+// Severity is the severity of a finding.
+type Severity string
+
+const (
+	// SeverityError is a finding that makes the configuration invalid.
+	SeverityError Severity = "error"
+	// SeverityWarning is a finding that does not make the configuration invalid.
+	SeverityWarning Severity = "warning"
+)
+
+// Rules identify the check that caused a finding.
+const (
+	// RuleManifest reports manifests that can not be decoded.
+	RuleManifest = "manifest"
+	// RuleSchema reports manifests that violate the OpenAPI schema of their kind.
+	RuleSchema = "schema"
+	// RuleKind reports manifests whose kind does not match their directory.
+	RuleKind = "kind"
+	// RuleUnknownSchema reports directories that are not in the schemas registry.
+	RuleUnknownSchema = "unknown-schema"
+	// RuleIntegrity reports manifests that are excluded from the integrity checks.
+	RuleIntegrity = "integrity"
+	// RuleDuplicateName reports resources of the same kind that share a name.
+	RuleDuplicateName = "duplicate-name"
+	// RuleUnknownReference reports references to resources that do not exist.
+	RuleUnknownReference = "unknown-reference"
+	// RuleDuplicateReference reports resources that are referenced more often than allowed.
+	RuleDuplicateReference = "duplicate-reference"
+	// RuleDuplicateMAC reports MAC addresses that are used more than once.
+	RuleDuplicateMAC = "duplicate-mac"
+	// RuleDuplicateDisk reports disks that are described more than once.
+	RuleDuplicateDisk = "duplicate-disk"
+	// RuleDuplicateAddress reports static addresses that are used by more than one Machine.
+	RuleDuplicateAddress = "duplicate-address"
+	// RuleInvalidNetwork reports inconsistent network configurations of Machines.
+	RuleInvalidNetwork = "invalid-network"
+	// RuleInvalidSelector reports MachinePools with an invalid selector.
+	RuleInvalidSelector = "invalid-selector"
+	// RuleOverlappingPools reports MachinePools that select the same Machines.
+	RuleOverlappingPools = "overlapping-pools"
+)
+
+// Finding is a problem in a configuration repository that
+// is reported with the manifest file that caused it.
+type Finding struct {
+	// File is the manifest file of the resource.
+	File string `json:"-"`
+	// Kind is the kind of the resource.
+	Kind string `json:"-"`
+	// Name is the name of the resource.
+	Name string `json:"-"`
+	// Severity is the severity of the finding.
+	Severity Severity `json:"severity"`
+	// Rule is the check that caused the finding.
+	Rule string `json:"rule"`
+	// Field is the path of the offending field, if any.
+	Field string `json:"field,omitempty"`
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+// String returns a human readable representation of the finding.
+func (f Finding) String() string {
+	location := fmt.Sprintf("%s %s", f.Kind, f.Name)
+	if f.Field != "" {
+		location = fmt.Sprintf("%s: %s", location, f.Field)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", f.File, location, f.Message)
+}
+
+// ValidationResult is the result of the validation of a single file.
+type ValidationResult struct {
+	// File is the validated file.
+	File string `json:"file"`
+	// Kind is the kind of the resource in the file, if known.
+	Kind string `json:"kind,omitempty"`
+	// Name is the name of the resource in the file, if known.
+	Name string `json:"name,omitempty"`
+	// Findings are the problems that were found in the file.
+	Findings []Finding `json:"findings"`
+}
+
+// ValidationReport collects the results of a validation.
+type ValidationReport struct {
+	results map[string]*ValidationResult
+}
+
+// NewValidationReport creates an empty validation report.
+func NewValidationReport() *ValidationReport {
+	return &ValidationReport{
+		results: make(map[string]*ValidationResult),
+	}
+}
+
+// Result returns the result of a file and adds it to the report if it does not exist.
+func (r *ValidationReport) Result(file string, kind string, name string) *ValidationResult {
+	result, ok := r.results[file]
+	if !ok {
+		result = &ValidationResult{
+			File:     file,
+			Findings: make([]Finding, 0),
+		}
+		r.results[file] = result
+	}
+
+	if result.Kind == "" {
+		result.Kind = kind
+	}
+	if result.Name == "" {
+		result.Name = name
+	}
+
+	return result
+}
+
+// Add adds a finding to the result of its file.
+func (r *ValidationReport) Add(finding Finding) {
+	result := r.Result(finding.File, finding.Kind, finding.Name)
+	result.Findings = append(result.Findings, finding)
+}
+
+// Failed returns true if the report contains an error for the file.
+func (r *ValidationReport) Failed(file string) bool {
+	result, ok := r.results[file]
+	if !ok {
+		return false
+	}
+
+	return slices.ContainsFunc(result.Findings, func(finding Finding) bool {
+		return finding.Severity == SeverityError
+	})
+}
+
+// Results returns the results sorted by file.
+func (r *ValidationReport) Results() []*ValidationResult {
+	results := make([]*ValidationResult, 0, len(r.results))
+	for _, result := range r.results {
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].File < results[j].File
+	})
+
+	return results
+}
+
+// Count returns the number of findings with the given severity.
+func (r *ValidationReport) Count(severity Severity) int {
+	count := 0
+	for _, result := range r.results {
+		for _, finding := range result.Findings {
+			if finding.Severity == severity {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// OutputFormats are the supported output formats of a validation report.
+var OutputFormats = []string{"text", "json", "sarif", "junit"}
+
+// Write writes the report in the given output format.
+func (r *ValidationReport) Write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		return r.writeText(w)
+	case "json":
+		return r.writeJSON(w)
+	case "sarif":
+		return r.writeSARIF(w)
+	case "junit":
+		return r.writeJUnit(w)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// writeText writes the report in a human readable format.
+func (r *ValidationReport) writeText(w io.Writer) error {
+	for _, result := range r.Results() {
+		if len(result.Findings) == 0 {
+			fmt.Fprintf(w, "🟢 >> %s\n", result.File)
+			continue
+		}
+
+		for _, finding := range result.Findings {
+			emoji := "🔴"
+			if finding.Severity == SeverityWarning {
+				emoji = "🟡"
+			}
+
+			message := finding.Message
+			if finding.Field != "" {
+				message = fmt.Sprintf("%s: %s", finding.Field, message)
+			}
+
+			fmt.Fprintf(w, "%s >> %s: %s\n", emoji, result.File, message)
+		}
+	}
+
+	fmt.Fprintf(w, "%d errors, %d warnings\n", r.Count(SeverityError), r.Count(SeverityWarning))
+
+	return nil
+}
+
+// writeJSON writes the report as JSON.
+func (r *ValidationReport) writeJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(struct {
+		Errors   int                 `json:"errors"`
+		Warnings int                 `json:"warnings"`
+		Results  []*ValidationResult `json:"results"`
+	}{
+		Errors:   r.Count(SeverityError),
+		Warnings: r.Count(SeverityWarning),
+		Results:  r.Results(),
+	})
+}
@@ -0,0 +1,336 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
+)
+
+// ConfigRepository is a configuration repository.
+type ConfigRepository struct {
+	Machines         cloud.MachineList
+	MachinePools     cloud.MachinePoolList
+	Regions          cloud.RegionList
+	HardwareProfiles cloud.HardwareProfileList
+
+	// Settings are the global settings that are used to render
+	// artifacts that do not only depend on the resources.
+	Settings *Settings
+
+	// Sources contains the manifest files that a resource was loaded from.
+	// A resource has multiple sources if its name is not unique.
+	Sources map[ResourceKey][]string
+}
+
+// ResourceKey identifies a resource in a configuration repository.
+type ResourceKey struct {
+	Kind string
+	Name string
+}
+
+// Source returns the manifest file that a resource was loaded from.
+func (r *ConfigRepository) Source(kind string, name string) string {
+	if sources := r.Sources[ResourceKey{Kind: kind, Name: name}]; len(sources) > 0 {
+		return sources[0]
+	}
+
+	return ""
+}
+
+// NewConfigRepository creates a new configuration repository.
+func NewConfigRepository() *ConfigRepository {
+	return &ConfigRepository{
+		Machines: cloud.MachineList{
+			Items: []cloud.Machine{},
+		},
+		MachinePools: cloud.MachinePoolList{
+			Items: []cloud.MachinePool{},
+		},
+		Regions: cloud.RegionList{
+			Items: []cloud.Region{},
+		},
+		HardwareProfiles: cloud.HardwareProfileList{
+			Items: []cloud.HardwareProfile{},
+		},
+		Sources: make(map[ResourceKey][]string),
+	}
+}
+
+// ManifestError is a manifest that could not be loaded.
+type ManifestError struct {
+	// File is the manifest file.
+	File string
+	// Err is the reason why the manifest could not be loaded.
+	Err error
+}
+
+// Error returns the file and the reason why it could not be loaded.
+func (e *ManifestError) Error() string {
+	return fmt.Sprintf("%s: %v", e.File, e.Err)
+}
+
+// Unwrap returns the reason why the manifest could not be loaded.
+func (e *ManifestError) Unwrap() error {
+	return e.Err
+}
+
+// ManifestErrors are the manifests of a schema directory that could not be loaded.
+type ManifestErrors []*ManifestError
+
+// Error returns the errors of all manifests.
+func (e ManifestErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// LoadConfigRepository loads all schemas of a configuration repository.
+// It fails if any manifest can not be loaded.
+func LoadConfigRepository(srcDir string) (*ConfigRepository, error) {
+	repository := NewConfigRepository()
+
+	for _, schema := range Schemas {
+		if err := schema.Load(repository, path.Join(srcDir, schema.Name)); err != nil {
+			return nil, fmt.Errorf("failed to load schema %s: %w", schema.Name, err)
+		}
+	}
+
+	return repository, nil
+}
+
+// LoadPartialConfigRepository loads all manifests of a configuration
+// repository that can be loaded and returns the manifests that can
+// not be loaded, so that the remaining resources can still be checked.
+func LoadPartialConfigRepository(srcDir string) (*ConfigRepository, []*ManifestError, error) {
+	repository := NewConfigRepository()
+	failed := make([]*ManifestError, 0)
+
+	for _, schema := range Schemas {
+		err := schema.Load(repository, path.Join(srcDir, schema.Name))
+
+		var manifestErrs ManifestErrors
+		if errors.As(err, &manifestErrs) {
+			failed = append(failed, manifestErrs...)
+			continue
+		}
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load schema %s: %w", schema.Name, err)
+		}
+	}
+
+	return repository, failed, nil
+}
+
+// ResourceLoader is a function that loads a resource into a repository.
+type ResourceLoader func(srcDir string) error
+
+// Load loads the configuration. This is not optimized for performance,
+// we should most likely make this concurrent. The manifest file of every
+// loaded resource is appended to files, if files is not nil. Manifests
+// that can not be decoded are skipped and returned as ManifestErrors.
+func Load[T any](repository *[]T, files *[]string) ResourceLoader {
+	return func(schemaDir string) error {
+		// Read files.
+		entries, err := os.ReadDir(schemaDir)
+		if err != nil {
+			// A repository does not need to contain every schema.
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return fmt.Errorf("failed to read schema directory: %w", err)
+		}
+
+		failed := make(ManifestErrors, 0)
+		for _, entry := range entries {
+			// We do not expect subdirectories.
+			if entry.IsDir() {
+				continue
+			}
+
+			resourceManifest := path.Join(schemaDir, entry.Name())
+
+			entity, err := DecodeManifest[T](resourceManifest)
+			if err != nil {
+				failed = append(failed, &ManifestError{File: resourceManifest, Err: err})
+				continue
+			}
+
+			*repository = append(*repository, *entity)
+			if files != nil {
+				*files = append(*files, resourceManifest)
+			}
+		}
+
+		if len(failed) > 0 {
+			return failed
+		}
+
+		return nil
+	}
+}
+
+// DecodeManifest reads a Kubernetes manifest from a file
+// and converts it into the given resource type.
+func DecodeManifest[T any](resourceManifest string) (*T, error) {
+	resource, err := DecodeUnstructured(resourceManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return ConvertManifest[T](resource)
+}
+
+// DecodeUnstructured reads a Kubernetes manifest from a file
+// without converting it into a specific resource type.
+func DecodeUnstructured(resourceManifest string) (*unstructured.Unstructured, error) {
+	rawResource, err := os.ReadFile(resourceManifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource: %w", err)
+	}
+
+	decoder := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
+
+	obj, _, err := decoder.Decode(rawResource, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode resource manifest: %w", err)
+	}
+
+	resource, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("failed to decode resource manifest: unexpected list")
+	}
+
+	return resource, nil
+}
+
+// ConvertManifest converts a decoded Kubernetes manifest into the given resource type.
+func ConvertManifest[T any](resource *unstructured.Unstructured) (*T, error) {
+	if err := checkKind[T](resource.GetAPIVersion(), resource.GetKind()); err != nil {
+		return nil, err
+	}
+
+	entity := new(T)
+
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(resource.UnstructuredContent(), entity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Kubernetes manifest: %w", err)
+	}
+
+	return entity, nil
+}
+
+// ResolveHardwareProfiles merges the referenced HardwareProfile
+// into the hardware configuration of every Machine.
+func (r *ConfigRepository) ResolveHardwareProfiles() error {
+	profiles := make(map[string]*cloud.HardwareProfile, len(r.HardwareProfiles.Items))
+	for index := range r.HardwareProfiles.Items {
+		profile := &r.HardwareProfiles.Items[index]
+		profiles[profile.Name] = profile
+	}
+
+	for index := range r.Machines.Items {
+		machine := &r.Machines.Items[index]
+
+		ref := machine.Spec.Hardware.ProfileRef
+		if ref == nil {
+			continue
+		}
+
+		profile, ok := profiles[ref.Name]
+		if !ok {
+			return fmt.Errorf("machine %s references unknown hardware profile: %s", machine.Name, ref.Name)
+		}
+
+		machine.Spec.Hardware.Merge(&profile.Spec)
+	}
+
+	return nil
+}
+
+// MachinesForPool returns the Machines that are matched by the selector of a MachinePool.
+func (r *ConfigRepository) MachinesForPool(pool *cloud.MachinePool) ([]*cloud.Machine, error) {
+	selector, err := pool.Spec.Selector.AsSelector()
+	if err != nil {
+		return nil, fmt.Errorf("machine pool %s has an invalid selector: %w", pool.Name, err)
+	}
+
+	machines := make([]*cloud.Machine, 0)
+	for index := range r.Machines.Items {
+		machine := &r.Machines.Items[index]
+
+		if selector.Matches(labels.Set(machine.Labels)) {
+			machines = append(machines, machine)
+		}
+	}
+
+	return machines, nil
+}
+
+// ResolveMachinePools reports the Machines matched by
+// every MachinePool in the status of the MachinePool.
+func (r *ConfigRepository) ResolveMachinePools() error {
+	for index := range r.MachinePools.Items {
+		pool := &r.MachinePools.Items[index]
+
+		machines, err := r.MachinesForPool(pool)
+		if err != nil {
+			return err
+		}
+
+		pool.Status.MachineCount = int32(len(machines))
+		pool.Status.ReadyMachineCount = 0
+		pool.Status.Machines = make([]string, 0, len(machines))
+		for _, machine := range machines {
+			pool.Status.Machines = append(pool.Status.Machines, machine.Name)
+
+			if meta.IsStatusConditionTrue(machine.Status.Conditions, cloud.MachineConditionReady) {
+				pool.Status.ReadyMachineCount++
+			}
+		}
+		sort.Strings(pool.Status.Machines)
+	}
+
+	return nil
+}
+
+// MachineAddresses returns the static addresses of a Machine followed by the
+// valid IP addresses that were observed on its interfaces in the order of the
+// interfaces without duplicates.
+func MachineAddresses(machine *cloud.Machine) []netip.Addr {
+	addresses := make([]netip.Addr, 0)
+	for _, prefix := range machine.Spec.StaticAddresses() {
+		if !slices.Contains(addresses, prefix.Addr()) {
+			addresses = append(addresses, prefix.Addr())
+		}
+	}
+
+	for _, iface := range machine.Status.Interfaces {
+		for _, address := range iface.Addresses {
+			addr, err := netip.ParseAddr(address)
+			if err != nil || slices.Contains(addresses, addr) {
+				continue
+			}
+
+			addresses = append(addresses, addr)
+		}
+	}
+
+	return addresses
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// The types below implement the subset of the Static Analysis Results
+// Interchange Format (SARIF) 2.1.0 that is required to report findings
+// to code scanning tools, such as GitHub code scanning.
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// writeSARIF writes the report as SARIF log.
+func (r *ValidationReport) writeSARIF(w io.Writer) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "labctl",
+				InformationURI: "https://github.com/nicklasfrahm/homelab",
+			},
+		},
+		Results: make([]sarifResult, 0),
+	}
+
+	for _, result := range r.Results() {
+		for _, finding := range result.Findings {
+			location := sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{
+						URI: filepath.ToSlash(result.File),
+					},
+				},
+			}
+
+			if result.Kind != "" && result.Name != "" {
+				name := fmt.Sprintf("%s/%s", result.Kind, result.Name)
+				if finding.Field != "" {
+					name = fmt.Sprintf("%s.%s", name, finding.Field)
+				}
+
+				location.LogicalLocations = []sarifLogicalLocation{
+					{FullyQualifiedName: name, Kind: "resource"},
+				}
+			}
+
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    finding.Rule,
+				Level:     string(finding.Severity),
+				Message:   sarifMessage{Text: finding.Message},
+				Locations: []sarifLocation{location},
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Schema describes a kind of resource that is stored in its own directory
+// of a configuration repository. Registering a schema in Schemas is all that
+// is needed for a kind to be loaded, validated and built.
+type Schema struct {
+	// Name is the name of the directory that contains the manifests.
+	Name string
+	// Kind is the kind of the resources, e.g. "Machine".
+	Kind string
+	// Type is the Go type of the resources.
+	Type reflect.Type
+	// Load loads all manifests of a schema directory into the repository.
+	Load func(repository *ConfigRepository, schemaDir string) error
+	// Validate checks that a decoded manifest can be converted into the Go type.
+	Validate func(resource *unstructured.Unstructured) error
+	// Render renders all resources of the repository into the artifacts.
+	Render func(repository *ConfigRepository, artifacts Artifacts) error
+	// Filter returns a list of the resources of the repository that
+	// match both the label selector and the field selector.
+	Filter func(repository *ConfigRepository, labelSelector labels.Selector, fieldSelector fields.Selector) (runtime.Object, error)
+}
+
+// Schemas contains all schemas that are supported by a configuration repository.
+var Schemas = []Schema{
+	NewSchema("machines", func(r *ConfigRepository) (*cloud.MachineList, *[]cloud.Machine) {
+		return &r.Machines, &r.Machines.Items
+	}),
+	NewSchema("machinepools", func(r *ConfigRepository) (*cloud.MachinePoolList, *[]cloud.MachinePool) {
+		return &r.MachinePools, &r.MachinePools.Items
+	}),
+	NewSchema("regions", func(r *ConfigRepository) (*cloud.RegionList, *[]cloud.Region) {
+		return &r.Regions, &r.Regions.Items
+	}),
+	NewSchema("hardwareprofiles", func(r *ConfigRepository) (*cloud.HardwareProfileList, *[]cloud.HardwareProfile) {
+		return &r.HardwareProfiles, &r.HardwareProfiles.Items
+	}),
+}
+
+// NewSchema creates a schema for a kind. The list function returns
+// the list of the kind in a repository and a pointer to its items.
+func NewSchema[T any, PT interface {
+	*T
+	CRD
+}, L runtime.Object](name string, list func(*ConfigRepository) (L, *[]T)) Schema {
+	typ := reflect.TypeFor[T]()
+
+	return Schema{
+		Name: name,
+		Kind: typ.Name(),
+		Type: typ,
+		Load: func(repository *ConfigRepository, schemaDir string) error {
+			_, items := list(repository)
+
+			// The sources of the loaded resources are also recorded if
+			// other manifests could not be loaded, see ManifestErrors.
+			offset := len(*items)
+			files := make([]string, 0)
+			err := Load(items, &files)(schemaDir)
+
+			for index, file := range files {
+				key := ResourceKey{
+					Kind: typ.Name(),
+					Name: PT(&(*items)[offset+index]).GetObjectMeta().GetName(),
+				}
+				repository.Sources[key] = append(repository.Sources[key], file)
+			}
+
+			return err
+		},
+		Validate: func(resource *unstructured.Unstructured) error {
+			_, err := ConvertManifest[T](resource)
+
+			return err
+		},
+		Render: func(repository *ConfigRepository, artifacts Artifacts) error {
+			resourceList, items := list(repository)
+
+			pointers := make([]PT, len(*items))
+			for index := range *items {
+				pointers[index] = PT(&(*items)[index])
+			}
+
+			return RenderAll(resourceList, pointers)(artifacts, name)
+		},
+		Filter: func(repository *ConfigRepository, labelSelector labels.Selector, fieldSelector fields.Selector) (runtime.Object, error) {
+			resourceList, items := list(repository)
+
+			matches := make([]runtime.Object, 0)
+			for index := range *items {
+				item := PT(&(*items)[index])
+
+				objectMeta := item.GetObjectMeta()
+				if labelSelector.Matches(labels.Set(objectMeta.GetLabels())) && fieldSelector.Matches(ObjectMetaFields(objectMeta)) {
+					matches = append(matches, item)
+				}
+			}
+
+			filtered := resourceList.DeepCopyObject()
+			if err := meta.SetList(filtered, matches); err != nil {
+				return nil, fmt.Errorf("failed to filter list: %w", err)
+			}
+
+			return filtered, nil
+		},
+	}
+}
+
+// LookupSchema returns the schema that is stored in the directory with the given name.
+func LookupSchema(name string) (Schema, bool) {
+	for _, schema := range Schemas {
+		if schema.Name == name {
+			return schema, true
+		}
+	}
+
+	return Schema{}, false
+}
+
+// checkKind ensures that a manifest contains a resource of the expected kind.
+func checkKind[T any](apiVersion string, kind string) error {
+	if apiVersion != cloud.GroupVersion.String() {
+		return fmt.Errorf("unsupported apiVersion: %s", apiVersion)
+	}
+
+	if expected := reflect.TypeFor[T]().Name(); kind != expected {
+		return fmt.Errorf("expected kind %s, got %s", expected, kind)
+	}
+
+	return nil
+}
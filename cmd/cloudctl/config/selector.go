@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+)
+
+// SelectableFields are the fields that are supported by field selectors.
+// Just like for custom resources in Kubernetes, only the name and the
+// namespace of a resource can be selected.
+var SelectableFields = []string{"metadata.name", "metadata.namespace"}
+
+// ObjectMetaFields returns the selectable fields of a resource.
+func ObjectMetaFields(objectMeta metav1.Object) fields.Set {
+	return fields.Set{
+		"metadata.name":      objectMeta.GetName(),
+		"metadata.namespace": objectMeta.GetNamespace(),
+	}
+}
+
+// ParseLabelSelector parses a label selector with the same
+// semantics as the selector of a MachinePool.
+func ParseLabelSelector(selector string) (labels.Selector, error) {
+	parsed, err := cloud.ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsed.AsSelector()
+}
+
+// ParseFieldSelector parses a field selector and ensures
+// that it only references the SelectableFields.
+func ParseFieldSelector(selector string) (fields.Selector, error) {
+	parsed, err := fields.ParseSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field selector: %w", err)
+	}
+
+	for _, requirement := range parsed.Requirements() {
+		if !slices.Contains(SelectableFields, requirement.Field) {
+			return nil, fmt.Errorf("field label not supported: %s", requirement.Field)
+		}
+	}
+
+	return parsed, nil
+}
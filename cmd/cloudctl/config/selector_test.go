@@ -0,0 +1,56 @@
+package config
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var _ = Describe("ParseLabelSelector", func() {
+	DescribeTable("should match labels",
+		func(selector string, set labels.Set, matches bool) {
+			parsed, err := ParseLabelSelector(selector)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed.Matches(set)).To(Equal(matches))
+		},
+		Entry("empty selector", "", labels.Set{"zone": "home"}, true),
+		Entry("equality", "zone=home", labels.Set{"zone": "home"}, true),
+		Entry("exclusion", "zone notin (home)", labels.Set{"zone": "home"}, false),
+		Entry("set", "zone in (home,office)", labels.Set{"zone": "office"}, true),
+		Entry("existence", "zone", labels.Set{"pool": "lab01"}, false),
+	)
+
+	DescribeTable("should reject selectors",
+		func(selector string) {
+			_, err := ParseLabelSelector(selector)
+			Expect(err).To(MatchError(ContainSubstring("invalid selector")))
+		},
+		Entry("with invalid syntax", "zone==="),
+		// The selector of a MachinePool can not express inequality.
+		Entry("with inequality", "zone!=home"),
+	)
+})
+
+var _ = Describe("ParseFieldSelector", func() {
+	DescribeTable("should match fields",
+		func(selector string, matches bool) {
+			parsed, err := ParseFieldSelector(selector)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed.Matches(fields.Set{"metadata.name": "ant", "metadata.namespace": ""})).To(Equal(matches))
+		},
+		Entry("empty selector", "", true),
+		Entry("name", "metadata.name=ant", true),
+		Entry("other name", "metadata.name=bee", false),
+		Entry("namespace", "metadata.namespace=default", false),
+	)
+
+	DescribeTable("should reject selectors",
+		func(selector string, message string) {
+			_, err := ParseFieldSelector(selector)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("with unsupported field", "spec.hardware.model=NUC", "field label not supported: spec.hardware.model"),
+		Entry("with invalid syntax", "metadata.name", "invalid field selector"),
+	)
+})
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultSettingsFile is the default path of the settings file.
+const DefaultSettingsFile = "config.yaml"
+
+// TalosPatchesDir is the directory of a configuration repository that
+// contains the patches for the Talos machine configuration of Machines.
+const TalosPatchesDir = "talos"
+
+// Settings are the global settings of the homelab
+// that are not part of any resource, e.g. pinned versions.
+type Settings struct {
+	// Talos contains the settings of Talos Linux.
+	Talos TalosSettings `json:"talos"`
+}
+
+// TalosSettings contains the settings of Talos Linux.
+type TalosSettings struct {
+	// Version is the desired version of Talos, e.g. "v1.9.3".
+	Version string `json:"version"`
+}
+
+// LoadSettings loads the settings from a YAML file. A missing
+// file is not an error and results in empty settings.
+func LoadSettings(file string) (*Settings, error) {
+	settings := &Settings{}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return settings, nil
+		}
+
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, settings); err != nil {
+		return nil, fmt.Errorf("failed to decode settings: %w", err)
+	}
+
+	return settings, nil
+}
@@ -1,102 +1,249 @@
 package config
 
 import (
-	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
+	"sigs.k8s.io/yaml"
 
-	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+	"github.com/nicklasfrahm/cloud/config/crd"
 )
 
 // ValidateCommand returns the validate command.
 func ValidateCommand() *cobra.Command {
-	return &cobra.Command{
+	var crdDir string
+	var output string
+
+	cmd := &cobra.Command{
 		Use:   "validate <directory>",
 		Short: "Validate manifests in a directory",
-		Long:  `Validate manifests in a directory.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Validate manifests in a directory.
+
+Every manifest is validated against the OpenAPI schema of the
+CustomResourceDefinition of its kind. By default the CRDs that
+are embedded into the binary are used.
+
+Afterwards the references between the resources are checked, e.g.
+that referenced Machines and HardwareProfiles exist, that names and
+MAC addresses are unique and that MachinePools do not overlap.
+
+The command exits with a non-zero exit code if any error is found.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
 				return fmt.Errorf("expected exactly one argument")
 			}
 
-			schemas := map[string]bool{
-				"machines": true,
+			if !slices.Contains(OutputFormats, output) {
+				return fmt.Errorf("unsupported output format: %s", output)
+			}
+
+			var crds fs.FS
+			if crdDir != "" {
+				crds = os.DirFS(crdDir)
+			} else {
+				bases, err := fs.Sub(crd.Bases, "bases")
+				if err != nil {
+					return fmt.Errorf("failed to load embedded CRDs: %w", err)
+				}
+				crds = bases
+			}
+
+			validator, err := NewOpenAPIValidator(crds)
+			if err != nil {
+				return fmt.Errorf("failed to create validator: %w", err)
 			}
 
-			// Read folders in directory and check if they are in the schemas map.
 			configDir := args[0]
-			entries, err := os.ReadDir(configDir)
+			report, err := Validate(configDir, validator)
 			if err != nil {
-				return fmt.Errorf("failed to read directory: %w", err)
+				return err
 			}
 
-			for _, entry := range entries {
-				// Skip files that are directly in the config directory.
-				if !entry.IsDir() {
-					continue
-				}
+			if err := report.Write(cmd.OutOrStdout(), output); err != nil {
+				return fmt.Errorf("failed to write report: %w", err)
+			}
 
-				// Check if the folder is in the schemas map.
-				if _, ok := schemas[entry.Name()]; !ok {
-					fmt.Printf("🟡 Skipping %s: not in schemas\n", entry.Name())
-					continue
-				}
+			if errors := report.Count(SeverityError); errors > 0 {
+				return fmt.Errorf("validation failed with %d errors", errors)
+			}
 
-				// Validate the schema of the directory.
-				fmt.Printf("🟢 Validating schema: %s\n", entry.Name())
+			return nil
+		},
+	}
 
-				schemaDir := path.Join(configDir, entry.Name())
+	cmd.Flags().StringVar(&crdDir, "crd-dir", "", "directory containing the CRDs to validate against instead of the embedded CRDs")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "output format, one of: "+strings.Join(OutputFormats, "|"))
 
-				switch entry.Name() {
-				case "machines":
-					if err := validateSchema[cloud.Machine](schemaDir); err != nil {
-						return fmt.Errorf("failed to validate schema: %w", err)
-					}
-				}
+	return cmd
+}
+
+// Validate validates all manifests in a configuration directory
+// and the references between them.
+func Validate(configDir string, validator *OpenAPIValidator) (*ValidationReport, error) {
+	report := NewValidationReport()
+
+	// Read folders in directory and check if they are in the schemas registry.
+	entries, err := os.ReadDir(configDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		// Skip files that are directly in the config directory.
+		if !entry.IsDir() {
+			continue
+		}
+
+		schemaDir := path.Join(configDir, entry.Name())
+
+		// Patches are not resources, but they must at least be valid YAML.
+		if entry.Name() == TalosPatchesDir {
+			if err := validatePatches(report, schemaDir); err != nil {
+				return nil, fmt.Errorf("failed to validate patches: %w", err)
 			}
+			continue
+		}
 
-			return nil
-		},
+		// Check if the folder is in the schemas registry.
+		schema, ok := LookupSchema(entry.Name())
+		if !ok {
+			report.Add(Finding{
+				File:     schemaDir,
+				Severity: SeverityWarning,
+				Rule:     RuleUnknownSchema,
+				Message:  "skipped, not in schemas",
+			})
+			continue
+		}
+
+		if err := validateSchema(report, schema, validator, schemaDir); err != nil {
+			return nil, fmt.Errorf("failed to validate schema: %w", err)
+		}
 	}
+
+	// References between resources can only be checked once the manifests
+	// of the repository are loaded. Manifests that can not be loaded are
+	// excluded, so that the other manifests are still checked.
+	repository, failed, err := LoadPartialConfigRepository(configDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, manifestErr := range failed {
+		// The exclusion is only an error if the schema validation
+		// did not already fail, so that errors are not counted twice.
+		severity := SeverityError
+		if report.Failed(manifestErr.File) {
+			severity = SeverityWarning
+		}
+
+		report.Add(Finding{
+			File:     manifestErr.File,
+			Severity: severity,
+			Rule:     RuleIntegrity,
+			Message:  fmt.Sprintf("excluded from integrity checks: %v", manifestErr.Err),
+		})
+	}
+
+	for _, finding := range repository.CheckIntegrity() {
+		report.Add(finding)
+	}
+
+	return report, nil
 }
 
-// validateSchema validates the schema of a directory.
-func validateSchema[T any](directory string) error {
-	// Read files in directory and check if they match the schema.
+// validatePatches validates that the patches in a directory are YAML maps.
+func validatePatches(report *ValidationReport, directory string) error {
 	entries, err := os.ReadDir(directory)
 	if err != nil {
 		return fmt.Errorf("failed to read directory: %w", err)
 	}
 
 	for _, entry := range entries {
-		// Skip directories.
 		if entry.IsDir() {
 			continue
 		}
 
-		// Read file content and unmarshal it into the schema.
-		filePath := path.Join(directory, entry.Name())
-		file, err := os.Open(filePath)
+		file := path.Join(directory, entry.Name())
+		report.Result(file, "", "")
+
+		data, err := os.ReadFile(file)
 		if err != nil {
-			return fmt.Errorf("failed to open file: %w", err)
+			return fmt.Errorf("failed to read patch: %w", err)
+		}
+
+		patch := make(map[string]any)
+		if err := yaml.Unmarshal(data, &patch); err != nil {
+			report.Add(Finding{
+				File:     file,
+				Severity: SeverityError,
+				Rule:     RuleManifest,
+				Message:  fmt.Sprintf("failed to decode patch: %v", err),
+			})
+		}
+	}
+
+	return nil
+}
+
+// validateSchema validates the manifests of a schema directory.
+func validateSchema(report *ValidationReport, schema Schema, validator *OpenAPIValidator, directory string) error {
+	// Read files in directory and check if they match the schema.
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		// Skip directories.
+		if entry.IsDir() {
+			continue
 		}
-		defer file.Close()
 
-		instance := new(T)
+		file := path.Join(directory, entry.Name())
 
-		decoder := yaml.NewDecoder(file)
-		if err := decoder.Decode(&instance); err != nil {
-			fmt.Printf("🔴 >> %s: %v\n", entry.Name(), errors.Unwrap(err))
+		resource, err := DecodeUnstructured(file)
+		if err != nil {
+			report.Add(Finding{
+				File:     file,
+				Kind:     schema.Kind,
+				Severity: SeverityError,
+				Rule:     RuleManifest,
+				Message:  err.Error(),
+			})
+			continue
+		}
 
+		// Register the file, so that it is reported even if it is valid.
+		report.Result(file, resource.GetKind(), resource.GetName())
+
+		if violations := validator.Validate(resource); len(violations) > 0 {
+			for _, violation := range violations {
+				report.Add(Finding{
+					File:     file,
+					Severity: SeverityError,
+					Rule:     RuleSchema,
+					Field:    violation.Field,
+					Message:  violation.ErrorBody(),
+				})
+			}
 			continue
 		}
 
-		fmt.Printf("🟢 >> %s\n", entry.Name())
+		if err := schema.Validate(resource); err != nil {
+			report.Add(Finding{
+				File:     file,
+				Severity: SeverityError,
+				Rule:     RuleKind,
+				Message:  err.Error(),
+			})
+		}
 	}
 
 	return nil
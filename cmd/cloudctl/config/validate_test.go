@@ -0,0 +1,43 @@
+package config
+
+import (
+	"io/fs"
+	"path"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/nicklasfrahm/cloud/config/crd"
+)
+
+var _ = Describe("Validate", func() {
+	var validator *OpenAPIValidator
+
+	BeforeEach(func() {
+		bases, err := fs.Sub(crd.Bases, "bases")
+		Expect(err).NotTo(HaveOccurred())
+
+		validator, err = NewOpenAPIValidator(bases)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not count manifests that fail the schema validation twice", func() {
+		dir := path.Join("testdata", "partial")
+
+		report, err := Validate(dir, validator)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Count(SeverityError)).To(Equal(2))
+
+		var findings []Finding
+		for _, result := range report.Results() {
+			if result.File == path.Join(dir, "machines", "cow.yaml") {
+				findings = result.Findings
+			}
+		}
+		Expect(findings).To(ContainElement(And(
+			HaveField("Rule", RuleIntegrity),
+			HaveField("Severity", SeverityWarning),
+		)))
+		Expect(findings).To(ContainElement(HaveField("Severity", SeverityError)))
+	})
+})
@@ -0,0 +1,69 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// AnsibleCommand returns the ansible command.
+func AnsibleCommand() *cobra.Command {
+	var manifestsDir string
+	var outputFile string
+	var host string
+
+	cmd := &cobra.Command{
+		Use:   "ansible",
+		Short: "Export the inventory as Ansible dynamic inventory",
+		Long: `Export the inventory as Ansible dynamic inventory.
+
+Every Machine that is not decommissioned becomes a host. Every
+MachinePool becomes a group prefixed with "pool_" and every label
+becomes a group prefixed with "label_". Characters that are not
+allowed in group names are replaced with underscores, e.g. the
+label "topology.kubernetes.io/zone=home" becomes the group
+"label_topology_kubernetes_io_zone_home". The variables of the
+hosts are derived from the Machines, e.g. vendor, model and macs.
+
+The command can be used as inventory script with a wrapper:
+
+  #!/bin/sh
+  exec labctl export ansible --manifests deploy/manifests "$@"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repository, err := loadRepository(manifestsDir)
+			if err != nil {
+				return err
+			}
+
+			inventory, err := repository.AnsibleInventory()
+			if err != nil {
+				return fmt.Errorf("failed to generate inventory: %w", err)
+			}
+
+			var document any = inventory
+			if host != "" {
+				hostVars, ok := inventory.HostVars[host]
+				if !ok {
+					return fmt.Errorf("host not found: %s", host)
+				}
+				document = hostVars
+			}
+
+			data, err := json.MarshalIndent(document, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode inventory: %w", err)
+			}
+
+			return writeOutput(outputFile, append(data, '\n'))
+		},
+	}
+
+	cmd.Flags().StringVar(&manifestsDir, "manifests", "deploy/manifests", "directory containing the manifests")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "-", "file to write the inventory to, - for stdout")
+	cmd.Flags().Bool("list", true, "print the whole inventory, which is the default")
+	cmd.Flags().StringVar(&host, "host", "", "only print the variables of the given host")
+
+	return cmd
+}
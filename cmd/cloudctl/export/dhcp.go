@@ -0,0 +1,103 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/config"
+)
+
+// DHCPCommand returns the dhcp command.
+func DHCPCommand() *cobra.Command {
+	var manifestsDir string
+	var outputFile string
+	var format string
+	var check bool
+	var options config.DHCPOptions
+
+	cmd := &cobra.Command{
+		Use:   "dhcp",
+		Short: "Export the inventory as DHCP configuration",
+		Long: `Export the inventory as DHCP configuration.
+
+Every interface of every Machine gets a static reservation with
+the name of the Machine as hostname and the first static IPv4
+address of the interface or the first observed one, if any.
+Decommissioned Machines are omitted. The following formats are supported:
+
+  dnsmasq  dhcp-host entries with a tag for every MachinePool
+  kea      reservations and client classes of the Kea DHCPv4 server
+  ethers   the format of /etc/ethers, e.g. for dnsmasq --read-ethers
+
+The formats dnsmasq and kea chainload iPXE if the URL of the iPXE
+script is set. PXE clients download iPXE via TFTP depending on
+their architecture, while iPXE clients receive the script.
+
+With --check, the configuration is compared with the output file
+instead of writing it. The differences are printed as a unified
+diff and the command fails if the file is out of date.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(config.DHCPFormats, format) {
+				return fmt.Errorf("unsupported format: %s", format)
+			}
+
+			repository, err := loadRepository(manifestsDir)
+			if err != nil {
+				return err
+			}
+
+			data, err := repository.DHCPConfig(format, options)
+			if err != nil {
+				return fmt.Errorf("failed to generate configuration: %w", err)
+			}
+
+			if !check {
+				return writeOutput(outputFile, data)
+			}
+
+			if outputFile == "-" {
+				return fmt.Errorf("an output file is required to check the configuration")
+			}
+
+			current, err := os.ReadFile(outputFile)
+			if err != nil {
+				return fmt.Errorf("failed to read output: %w", err)
+			}
+
+			if bytes.Equal(current, data) {
+				fmt.Fprintf(os.Stderr, "🟢 Configuration is up to date: %s\n", outputFile)
+				return nil
+			}
+
+			diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(string(current)),
+				B:        difflib.SplitLines(string(data)),
+				FromFile: outputFile,
+				ToFile:   "inventory",
+				Context:  3,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to compare configuration: %w", err)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), diff)
+
+			return fmt.Errorf("configuration is out of date: %s", outputFile)
+		},
+	}
+
+	cmd.Flags().StringVar(&manifestsDir, "manifests", "deploy/manifests", "directory containing the manifests")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "-", "file to write the configuration to, - for stdout")
+	cmd.Flags().StringVarP(&format, "format", "f", "dnsmasq", "format of the configuration, one of: "+strings.Join(config.DHCPFormats, ", "))
+	cmd.Flags().BoolVar(&check, "check", false, "compare the configuration with the output file instead of writing it")
+	cmd.Flags().StringVar(&options.ScriptURL, "ipxe-script-url", "", "URL of the iPXE script, enables chainloading of iPXE")
+	cmd.Flags().StringVar(&options.TFTPServer, "tftp-server", "", "address of the TFTP server that serves iPXE (default DHCP server)")
+
+	return cmd
+}
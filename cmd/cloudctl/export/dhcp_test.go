@@ -0,0 +1,78 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DHCPCommand", func() {
+	// execute runs the command with the arguments and returns its output.
+	execute := func(args ...string) (string, error) {
+		output := &bytes.Buffer{}
+
+		cmd := DHCPCommand()
+		cmd.SetArgs(append([]string{"--manifests", path.Join("testdata", "manifests")}, args...))
+		cmd.SetOut(output)
+		cmd.SetErr(output)
+		cmd.SilenceUsage = true
+
+		err := cmd.Execute()
+
+		return output.String(), err
+	}
+
+	// outputFile copies the file in testdata to a temporary directory.
+	outputFile := func(name string, update func([]byte) []byte) string {
+		data, err := os.ReadFile(path.Join("testdata", name))
+		Expect(err).NotTo(HaveOccurred())
+
+		file := path.Join(GinkgoT().TempDir(), name)
+		Expect(os.WriteFile(file, update(data), 0644)).To(Succeed())
+
+		return file
+	}
+
+	unchanged := func(data []byte) []byte { return data }
+
+	It("should write the configuration to the output file", func() {
+		file := path.Join(GinkgoT().TempDir(), "ethers")
+
+		_, err := execute("--format", "ethers", "--output", file)
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := os.ReadFile(file)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal([]byte("# Generated by labctl export dhcp. Do not edit.\n02:00:00:00:00:01 10.0.0.10\n")))
+	})
+
+	It("should accept an output file that is up to date", func() {
+		output, err := execute("--format", "ethers", "--check", "--output", outputFile("ethers", unchanged))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output).To(BeEmpty())
+	})
+
+	It("should print the differences to an outdated output file", func() {
+		file := outputFile("ethers", func(data []byte) []byte {
+			return bytes.ReplaceAll(data, []byte("10.0.0.10"), []byte("10.0.0.99"))
+		})
+
+		output, err := execute("--format", "ethers", "--check", "--output", file)
+		Expect(err).To(MatchError("configuration is out of date: " + file))
+		Expect(output).To(ContainSubstring("--- " + file + "\n+++ inventory\n"))
+		Expect(output).To(ContainSubstring("-02:00:00:00:00:01 10.0.0.99\n+02:00:00:00:00:01 10.0.0.10\n"))
+	})
+
+	DescribeTable("should reject",
+		func(message string, args ...string) {
+			_, err := execute(args...)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("an unsupported format", "unsupported format: isc", "--format", "isc"),
+		Entry("a check without output file", "an output file is required", "--check"),
+		Entry("a check of a missing output file", "failed to read output", "--check", "--output", path.Join("testdata", "missing")),
+	)
+})
@@ -0,0 +1,115 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/netip"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/config"
+)
+
+// DNSCommand returns the dns command.
+func DNSCommand() *cobra.Command {
+	var manifestsDir string
+	var outputDir string
+	var nameserverAddresses []net.IP
+	var options config.DNSOptions
+
+	cmd := &cobra.Command{
+		Use:   "dns",
+		Short: "Export the inventory as DNS zone files",
+		Long: `Export the inventory as DNS zone files.
+
+A forward zone with A and AAAA records is generated for the domain
+and a reverse zone with PTR records for every /24 IPv4 network and
+every /64 IPv6 network of the static and observed addresses of the
+Machines. Decommissioned Machines are omitted. Every zone is written
+to a file named after its origin, e.g. lab.example.com.zone and
+0.0.10.in-addr.arpa.zone.
+
+The serial of a zone is kept if the existing zone file in the output
+directory has the same content. Otherwise, it is set to the current
+Unix time or, if the existing serial is not lower, increased by one.
+Keep the zone files, e.g. in the repository, for the serial to
+increase monotonically. The serial is not coordinated between
+multiple primaries, so generate the zones on a single primary.
+
+A nameserver that is part of the domain requires glue records, so
+its addresses must be provided, unless it is a Machine.
+
+If enabled, SRV records point at the control planes of every
+Region, e.g. _kubernetes._tcp.lab01.lab.example.com. Control planes
+without address or that are decommissioned are skipped.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repository, err := loadRepository(manifestsDir)
+			if err != nil {
+				return err
+			}
+
+			for _, ip := range nameserverAddresses {
+				addr, _ := netip.AddrFromSlice(ip)
+				options.NameserverAddresses = append(options.NameserverAddresses, addr.Unmap())
+			}
+
+			zones, err := repository.DNSZones(options)
+			if err != nil {
+				return fmt.Errorf("failed to generate zones: %w", err)
+			}
+
+			now := time.Now()
+			if outputDir == "-" {
+				for index, zone := range zones {
+					if index > 0 {
+						fmt.Println()
+					}
+					zone.UpdateSerial(nil, now)
+					fmt.Print(zone.String())
+				}
+
+				return nil
+			}
+
+			if err := os.MkdirAll(outputDir, 0755); err != nil {
+				return fmt.Errorf("failed to create output directory: %w", err)
+			}
+
+			for _, zone := range zones {
+				file := path.Join(outputDir, strings.TrimSuffix(zone.Origin, ".")+".zone")
+				previous, err := os.ReadFile(file)
+				if err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return fmt.Errorf("failed to read zone file: %w", err)
+				}
+
+				zone.UpdateSerial(previous, now)
+				if err := writeOutput(file, []byte(zone.String())); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(os.Stderr, "🟢 Generated zone %s: %s\n", zone.Origin, file)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&manifestsDir, "manifests", "deploy/manifests", "directory containing the manifests")
+	cmd.Flags().StringVarP(&outputDir, "output", "o", ".", "directory to write the zone files to, - for stdout")
+	cmd.Flags().StringVar(&options.Domain, "domain", "", "domain of the forward zone, e.g. lab.example.com")
+	cmd.Flags().StringVar(&options.Nameserver, "nameserver", "", "authoritative nameserver of the zones, relative to the domain unless it ends with a dot (default ns1.<domain>.)")
+	cmd.Flags().IPSliceVar(&nameserverAddresses, "nameserver-address", nil, "addresses of the nameserver for the glue records if it is part of the domain")
+	cmd.Flags().StringVar(&options.Hostmaster, "hostmaster", "", "mailbox of the SOA records (default hostmaster.<domain>.)")
+	cmd.Flags().IntVar(&options.TTL, "ttl", 3600, "default TTL of the records in seconds")
+	cmd.Flags().BoolVar(&options.SRV, "srv", false, "add SRV records for the control planes of every region")
+	cmd.MarkFlagRequired("domain")
+
+	return cmd
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/config"
+)
+
+// RootCommand returns the export command.
+func RootCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the inventory to other tools",
+		Long:  `Export the inventory to other tools.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(AnsibleCommand())
+	cmd.AddCommand(PrometheusCommand())
+	cmd.AddCommand(DNSCommand())
+	cmd.AddCommand(DHCPCommand())
+
+	return cmd
+}
+
+// loadRepository loads the configuration repository
+// and resolves the hardware profiles of the Machines.
+func loadRepository(manifestsDir string) (*config.ConfigRepository, error) {
+	repository, err := config.LoadConfigRepository(manifestsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repository.ResolveHardwareProfiles(); err != nil {
+		return nil, fmt.Errorf("failed to resolve hardware profiles: %w", err)
+	}
+
+	return repository, nil
+}
+
+// writeOutput writes the data to the file or to stdout if the file is "-".
+func writeOutput(file string, data []byte) error {
+	if file == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
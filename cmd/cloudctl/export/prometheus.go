@@ -0,0 +1,67 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/config"
+)
+
+// PrometheusCommand returns the prometheus command.
+func PrometheusCommand() *cobra.Command {
+	var manifestsDir string
+	var outputFile string
+	var port int
+	var blackbox bool
+
+	cmd := &cobra.Command{
+		Use:   "prometheus",
+		Short: "Export the inventory as Prometheus targets",
+		Long: `Export the inventory as Prometheus targets.
+
+The targets are written in the format of file_sd_configs with a
+target group for every MachinePool and a final group for the
+Machines that are not part of any pool. A Machine that is part of
+more than one pool is only added to the first pool by name, so that
+it is not scraped twice. Decommissioned Machines are omitted. The target of a Machine is its first static or
+observed address or its name. Labels that all Machines of a group share
+are added to the group, e.g. vendor, model and the labels of the
+Machines prefixed with "label_".
+
+The same targets are rendered by "labctl config build" into
+prometheus/node.json and prometheus/blackbox.json.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repository, err := loadRepository(manifestsDir)
+			if err != nil {
+				return err
+			}
+
+			// Blackbox probes target the hosts themselves.
+			if blackbox {
+				port = 0
+			}
+
+			groups, err := repository.PrometheusTargets(port)
+			if err != nil {
+				return fmt.Errorf("failed to generate targets: %w", err)
+			}
+
+			data, err := json.MarshalIndent(groups, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode targets: %w", err)
+			}
+
+			return writeOutput(outputFile, append(data, '\n'))
+		},
+	}
+
+	cmd.Flags().StringVar(&manifestsDir, "manifests", "deploy/manifests", "directory containing the manifests")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "-", "file to write the targets to, - for stdout")
+	cmd.Flags().IntVar(&port, "port", config.PrometheusNodeExporterPort, "port of the exporter on the machines")
+	cmd.Flags().BoolVar(&blackbox, "blackbox", false, "export targets without port for blackbox probes")
+
+	return cmd
+}
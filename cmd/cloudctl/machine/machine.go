@@ -0,0 +1,132 @@
+package machine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/config"
+)
+
+// RootCommand returns the machine command.
+func RootCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "machine",
+		Short: "Manage Machines",
+		Long:  `Manage Machines.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(PowerCommand())
+	cmd.AddCommand(WakeCommand())
+
+	return cmd
+}
+
+// selection selects Machines by their name,
+// by a MachinePool or by a label selector.
+type selection struct {
+	pool     string
+	selector string
+}
+
+// addFlags adds the flags of the selection to the command.
+func (s *selection) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&s.pool, "pool", "", "select the machines of a machine pool")
+	cmd.Flags().StringVarP(&s.selector, "selector", "l", "", "select the machines by a label selector, e.g. topology.kubernetes.io/zone=home")
+	cmd.MarkFlagsMutuallyExclusive("pool", "selector")
+}
+
+// machines returns the Machines that are selected by the names, the
+// MachinePool or the label selector, sorted by their name. Decommissioned
+// Machines are only returned if they are selected by their name.
+func (s *selection) machines(repository *config.ConfigRepository, names []string) ([]*cloud.Machine, error) {
+	if len(names) > 0 && (s.pool != "" || s.selector != "") {
+		return nil, fmt.Errorf("machines must be selected either by name or by --pool or --selector")
+	}
+
+	machines := make([]*cloud.Machine, 0)
+	switch {
+	case len(names) > 0:
+		for _, name := range names {
+			machine, err := findMachine(repository, name)
+			if err != nil {
+				return nil, err
+			}
+
+			machines = append(machines, machine)
+		}
+	case s.pool != "":
+		pool, err := findMachinePool(repository, s.pool)
+		if err != nil {
+			return nil, err
+		}
+
+		selected, err := repository.MachinesForPool(pool)
+		if err != nil {
+			return nil, err
+		}
+
+		machines = active(selected)
+	case s.selector != "":
+		selector, err := config.ParseLabelSelector(s.selector)
+		if err != nil {
+			return nil, err
+		}
+
+		selected := make([]*cloud.Machine, 0)
+		for index := range repository.Machines.Items {
+			machine := &repository.Machines.Items[index]
+			if selector.Matches(labels.Set(machine.Labels)) {
+				selected = append(selected, machine)
+			}
+		}
+
+		machines = active(selected)
+	default:
+		return nil, fmt.Errorf("machines must be selected by name or by --pool or --selector")
+	}
+
+	slices.SortFunc(machines, func(a, b *cloud.Machine) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return slices.CompactFunc(machines, func(a, b *cloud.Machine) bool {
+		return a.Name == b.Name
+	}), nil
+}
+
+// active returns the Machines that are not decommissioned.
+func active(machines []*cloud.Machine) []*cloud.Machine {
+	return slices.DeleteFunc(machines, func(machine *cloud.Machine) bool {
+		return machine.Spec.Decommissioned
+	})
+}
+
+// findMachine returns the machine with the given name.
+func findMachine(repository *config.ConfigRepository, name string) (*cloud.Machine, error) {
+	for index := range repository.Machines.Items {
+		if machine := &repository.Machines.Items[index]; machine.Name == name {
+			return machine, nil
+		}
+	}
+
+	return nil, fmt.Errorf("machine not found: %s", name)
+}
+
+// findMachinePool returns the machine pool with the given name.
+func findMachinePool(repository *config.ConfigRepository, name string) (*cloud.MachinePool, error) {
+	for index := range repository.MachinePools.Items {
+		if pool := &repository.MachinePools.Items[index]; pool.Name == name {
+			return pool, nil
+		}
+	}
+
+	return nil, fmt.Errorf("machine pool not found: %s", name)
+}
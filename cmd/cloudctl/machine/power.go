@@ -0,0 +1,155 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/config"
+	"github.com/nicklasfrahm/cloud/pkg/power"
+)
+
+// powerActions are the subcommands of the power command
+// with the operation of the driver that they invoke.
+var powerActions = []struct {
+	name  string
+	short string
+	run   func(ctx context.Context, driver power.Driver) error
+}{
+	{"on", "Power on Machines", func(ctx context.Context, driver power.Driver) error { return driver.On(ctx) }},
+	{"off", "Power off Machines immediately", func(ctx context.Context, driver power.Driver) error { return driver.Off(ctx) }},
+	{"cycle", "Power cycle Machines", func(ctx context.Context, driver power.Driver) error { return driver.Cycle(ctx) }},
+}
+
+// PowerCommand returns the power command.
+func PowerCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "power",
+		Short: "Manage the power of Machines",
+		Long: `Manage the power of Machines.
+
+The power of a Machine is controlled via the driver that is configured
+in spec.power, e.g. the Redfish API or IPMI of its BMC, the outlet of a
+smart PDU or Wake-on-LAN. Machines can be selected by their name, by a
+MachinePool or by a label selector. Machines without spec.power are
+skipped.
+
+The credentials of the power controllers are not part of the manifests,
+so they need to be passed via flags.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	for _, action := range powerActions {
+		cmd.AddCommand(powerCommand(action.name, action.short, func(ctx context.Context, machine *cloud.Machine, driver power.Driver) error {
+			if err := action.run(ctx, driver); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "🟢 Powered %s machine %s\n", action.name, machine.Name)
+			return nil
+		}))
+	}
+
+	cmd.AddCommand(powerCommand("status", "Show the power state of Machines", func(ctx context.Context, machine *cloud.Machine, driver power.Driver) error {
+		state, err := driver.State(ctx)
+		if err != nil {
+			return err
+		}
+
+		emoji := "🟡"
+		switch state {
+		case power.StateOn:
+			emoji = "🟢"
+		case power.StateOff:
+			emoji = "🔴"
+		}
+
+		fmt.Printf("%s %s: %s\n", emoji, machine.Name, state)
+		return nil
+	}))
+
+	return cmd
+}
+
+// powerCommand returns a subcommand of the power command that
+// invokes the function for the driver of every selected Machine.
+func powerCommand(name string, short string, run func(ctx context.Context, machine *cloud.Machine, driver power.Driver) error) *cobra.Command {
+	var manifestsDir string
+	var credentials power.Credentials
+	var selection selection
+
+	cmd := &cobra.Command{
+		Use:   name + " [name...]",
+		Short: short,
+		Long:  short + ".",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repository, err := config.LoadConfigRepository(manifestsDir)
+			if err != nil {
+				return err
+			}
+
+			machines, err := selection.machines(repository, args)
+			if err != nil {
+				return err
+			}
+
+			if len(machines) == 0 {
+				fmt.Fprintln(os.Stderr, "🟡 No machines selected")
+				return nil
+			}
+
+			failed := 0
+			for _, machine := range machines {
+				if machine.Spec.Power == nil {
+					fmt.Fprintf(os.Stderr, "🟡 Skipped machine %s: power management is not configured\n", machine.Name)
+					continue
+				}
+
+				driver, err := power.NewDriver(powerOptions(machine, credentials))
+				if err == nil {
+					err = run(cmd.Context(), machine, driver)
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "🔴 Failed to power %s machine %s: %v\n", name, machine.Name, err)
+					failed++
+				}
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("failed to power %s %d of %d machines", name, failed, len(machines))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&manifestsDir, "manifests", "deploy/manifests", "directory containing the manifests")
+	cmd.Flags().StringVarP(&credentials.Username, "username", "u", "", "username of the power controllers")
+	cmd.Flags().StringVarP(&credentials.Password, "password", "p", "", "password of the power controllers")
+	selection.addFlags(cmd)
+
+	return cmd
+}
+
+// powerOptions returns the options of the driver of a Machine.
+func powerOptions(machine *cloud.Machine, credentials power.Credentials) power.Options {
+	options := power.Options{
+		Driver:             string(machine.Spec.Power.Driver),
+		Endpoint:           machine.Spec.Power.Endpoint,
+		Outlet:             int(machine.Spec.Power.Outlet),
+		Credentials:        credentials,
+		InsecureSkipVerify: machine.Spec.Power.InsecureSkipVerify,
+	}
+
+	for _, iface := range machine.Spec.Interfaces {
+		options.MACs = append(options.MACs, net.HardwareAddr(iface.MAC))
+	}
+
+	return options
+}
@@ -0,0 +1,109 @@
+package machine
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/config"
+	"github.com/nicklasfrahm/cloud/pkg/wol"
+)
+
+// WakeCommand returns the wake command.
+func WakeCommand() *cobra.Command {
+	var manifestsDir string
+	var address string
+	var ifaceName string
+	var password string
+	var dryRun bool
+	var selection selection
+
+	cmd := &cobra.Command{
+		Use:   "wake [name...]",
+		Short: "Wake Machines via Wake-on-LAN",
+		Long: `Wake Machines via Wake-on-LAN.
+
+A magic packet is sent to every interface of the selected Machines.
+Machines can be selected by their name, by a MachinePool or by a
+label selector. Decommissioned Machines are only woken if they are
+selected by their name.
+
+By default, the magic packets are sent via UDP to the limited
+broadcast address. If an interface is set, the magic packets are
+broadcasted as raw Ethernet frames on the interface instead, which
+requires the capability CAP_NET_RAW and is only supported on Linux.
+
+Network cards that support SecureOn only wake up if the magic
+packet contains the password, which consists of 4 or 6 bytes in the
+format of an IPv4 or MAC address.`,
+		Example: `  labctl machine wake ant
+  labctl machine wake --pool lab01 --interface eth0
+  labctl machine wake --selector topology.kubernetes.io/zone=home --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			secret, err := wol.ParsePassword(password)
+			if err != nil {
+				return err
+			}
+
+			repository, err := config.LoadConfigRepository(manifestsDir)
+			if err != nil {
+				return err
+			}
+
+			machines, err := selection.machines(repository, args)
+			if err != nil {
+				return err
+			}
+
+			if len(machines) == 0 {
+				fmt.Fprintln(os.Stderr, "🟡 No machines selected")
+				return nil
+			}
+
+			target := fmt.Sprintf("udp %s", address)
+			if ifaceName != "" {
+				target = fmt.Sprintf("raw %s", ifaceName)
+			}
+
+			for _, machine := range machines {
+				for _, iface := range machine.Spec.Interfaces {
+					packet, err := wol.MagicPacket(net.HardwareAddr(iface.MAC), secret)
+					if err != nil {
+						return fmt.Errorf("failed to create magic packet for machine %s: %w", machine.Name, err)
+					}
+
+					if dryRun {
+						fmt.Printf("🟡 Would wake %s (%s) via %s\n%s", machine.Name, iface.MAC, target, hex.Dump(packet))
+						continue
+					}
+
+					if ifaceName != "" {
+						err = wol.SendRaw(ifaceName, packet)
+					} else {
+						err = wol.SendUDP(address, packet)
+					}
+					if err != nil {
+						return fmt.Errorf("failed to wake machine %s: %w", machine.Name, err)
+					}
+
+					fmt.Fprintf(os.Stderr, "🟢 Sent magic packet to %s (%s) via %s\n", machine.Name, iface.MAC, target)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&manifestsDir, "manifests", "deploy/manifests", "directory containing the manifests")
+	cmd.Flags().StringVar(&address, "address", wol.DefaultAddress, "broadcast address to send the magic packets to via UDP")
+	cmd.Flags().StringVarP(&ifaceName, "interface", "i", "", "network interface to broadcast raw Ethernet frames on instead of UDP")
+	cmd.Flags().StringVar(&password, "password", "", "SecureOn password, e.g. 00:11:22:33:44:55")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the magic packets instead of sending them")
+	selection.addFlags(cmd)
+	cmd.MarkFlagsMutuallyExclusive("address", "interface")
+
+	return cmd
+}
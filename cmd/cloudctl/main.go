@@ -4,6 +4,10 @@ import (
 	"os"
 
 	"github.com/nicklasfrahm/cloud/cmd/cloudctl/config"
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/export"
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/machine"
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/serve"
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/talos"
 	"github.com/spf13/cobra"
 )
 
@@ -32,6 +36,10 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&help, "help", "h", false, "display help for command")
 
 	rootCmd.AddCommand(config.RootCommand())
+	rootCmd.AddCommand(export.RootCommand())
+	rootCmd.AddCommand(machine.RootCommand())
+	rootCmd.AddCommand(serve.RootCommand())
+	rootCmd.AddCommand(talos.RootCommand())
 }
 
 func main() {
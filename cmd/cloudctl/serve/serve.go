@@ -0,0 +1,132 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/config"
+	"github.com/nicklasfrahm/cloud/pkg/tftp"
+)
+
+// RootCommand returns the serve command.
+func RootCommand() *cobra.Command {
+	var address string
+	var settingsFile string
+	var watch bool
+	var tftpAddress string
+	var tftpRoot string
+
+	cmd := &cobra.Command{
+		Use:   "serve <src_dir>",
+		Short: "Serve configuration locally",
+		Long: `Serve configuration locally.
+
+The configuration is rendered in memory and served with the same
+routes as the static API, e.g. GET /v1beta1/machines returns the
+MachineList and GET /v1beta1/machines/{name} a single Machine.
+The configuration is reloaded when files in the source directory
+change. If the configuration becomes invalid, the previous
+configuration continues to be served.
+
+If a TFTP address is given, a read-only TFTP server serves the
+rendered artifacts without version prefix, e.g. the boot script
+boot/{mac}.ipxe, and the files in the TFTP root directory, e.g.
+the iPXE binaries that netboot clients chainload.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("expected exactly one argument")
+			}
+
+			logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+			server := NewServer(args[0], settingsFile, logger)
+			if err := server.Reload(); err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if watch {
+				go func() {
+					if err := server.Watch(ctx.Done()); err != nil {
+						logger.Error("Failed to watch configuration", "error", err)
+					}
+				}()
+			}
+
+			if tftpAddress != "" {
+				tftpServer := tftp.NewServer(tftpHandler(server, tftpRoot), logger)
+
+				go func() {
+					logger.Info("Serving TFTP", "address", tftpAddress, "root", tftpRoot)
+					if err := tftpServer.ListenAndServe(ctx, tftpAddress); err != nil {
+						logger.Error("Failed to serve TFTP", "error", err)
+						stop()
+					}
+				}()
+			}
+
+			httpServer := &http.Server{
+				Addr:              address,
+				Handler:           server,
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			go func() {
+				<-ctx.Done()
+
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				if err := httpServer.Shutdown(shutdownCtx); err != nil {
+					logger.Error("Failed to shut down server", "error", err)
+				}
+			}()
+
+			logger.Info("Serving configuration", "address", address, "source", args[0])
+			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("failed to serve: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&address, "address", ":8080", "address to listen on")
+	cmd.Flags().StringVar(&settingsFile, "config", config.DefaultSettingsFile, "settings file that pins versions, e.g. of Talos")
+	cmd.Flags().BoolVar(&watch, "watch", true, "reload the configuration when files change")
+	cmd.Flags().StringVar(&tftpAddress, "tftp-address", "", "address to serve TFTP on, e.g. :69, disabled if empty")
+	cmd.Flags().StringVar(&tftpRoot, "tftp-root", "", "directory with additional files to serve via TFTP, e.g. iPXE binaries")
+
+	return cmd
+}
+
+// tftpHandler serves the rendered artifacts of the server and falls
+// back to the files in the root directory if no artifact exists.
+func tftpHandler(server *Server, root string) tftp.Handler {
+	return tftp.HandlerFunc(func(name string) ([]byte, error) {
+		// Clients may use absolute paths, but the root directory must not be escaped.
+		name = strings.TrimPrefix(path.Clean("/"+name), "/")
+
+		data, err := server.ReadFile(name)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) || root == "" {
+			return data, err
+		}
+
+		return fs.ReadFile(os.DirFS(root), name)
+	})
+}
@@ -0,0 +1,13 @@
+package serve
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestServe(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "serve suite")
+}
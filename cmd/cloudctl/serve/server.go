@@ -0,0 +1,323 @@
+package serve
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/config"
+)
+
+// reloadDelay is the time to wait for further changes before reloading,
+// because editors usually emit multiple events when saving a file.
+const reloadDelay = 250 * time.Millisecond
+
+func init() {
+	// The iPXE scripts are plain text, but not known to the mime package.
+	if err := mime.AddExtensionType(".ipxe", "text/plain; charset=utf-8"); err != nil {
+		panic(err)
+	}
+}
+
+// Server serves the rendered artifacts of a configuration repository with
+// the same routes as the static API, which are documented in docs/api.md.
+type Server struct {
+	srcDir       string
+	settingsFile string
+	logger       *slog.Logger
+
+	mutex      sync.RWMutex
+	repository *config.ConfigRepository
+	artifacts  config.Artifacts
+}
+
+// NewServer creates a server for the configuration repository in srcDir.
+func NewServer(srcDir string, settingsFile string, logger *slog.Logger) *Server {
+	return &Server{
+		srcDir:       srcDir,
+		settingsFile: settingsFile,
+		logger:       logger,
+		repository:   config.NewConfigRepository(),
+		artifacts:    make(config.Artifacts),
+	}
+}
+
+// Reload loads and renders the configuration repository. If this
+// fails, the previously loaded artifacts continue to be served.
+func (s *Server) Reload() error {
+	repository, err := config.LoadConfigRepository(s.srcDir)
+	if err != nil {
+		return err
+	}
+
+	repository.Settings, err = config.LoadSettings(s.settingsFile)
+	if err != nil {
+		return err
+	}
+
+	artifacts, err := repository.Render()
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.repository = repository
+	s.artifacts = artifacts
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// Watch reloads the configuration repository whenever a file in the source
+// directory changes. It blocks until the done channel is closed.
+func (s *Server) Watch(done <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// Watchers are not recursive, so every directory is added individually.
+	err = filepath.WalkDir(s.srcDir, func(name string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return watcher.Add(name)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch source directory: %w", err)
+	}
+
+	var timer <-chan time.Time
+	for {
+		select {
+		case <-done:
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			// Watch directories that are created after startup.
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := watcher.Add(event.Name); err != nil {
+						s.logger.Error("Failed to watch directory", "directory", event.Name, "error", err)
+					}
+				}
+			}
+
+			timer = time.After(reloadDelay)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			s.logger.Error("Failed to watch source directory", "error", err)
+		case <-timer:
+			timer = nil
+
+			if err := s.Reload(); err != nil {
+				s.logger.Error("Failed to reload configuration, serving previous configuration", "error", err)
+				continue
+			}
+
+			s.logger.Info("Reloaded configuration")
+		}
+	}
+}
+
+// ServeHTTP serves the artifacts. A path without file extension is resolved
+// like a Kubernetes API path, e.g. /v1beta1/machines is resolved to the
+// file machines/index.json and /v1beta1/machines/ant to machines/ant.json.
+// List endpoints support the labelSelector and fieldSelector parameters.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.writeStatus(w, http.StatusMethodNotAllowed, metav1.StatusReasonMethodNotAllowed,
+			fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+
+	prefix := "/" + cloud.GroupVersion.Version + "/"
+	urlPath := path.Clean(r.URL.Path)
+	if !strings.HasPrefix(urlPath, prefix) {
+		s.writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound, "the server could not find the requested resource")
+		return
+	}
+
+	name := strings.TrimPrefix(urlPath, prefix)
+
+	query := r.URL.Query()
+	if query.Has("labelSelector") || query.Has("fieldSelector") {
+		s.serveList(w, r, name, query.Get("labelSelector"), query.Get("fieldSelector"))
+		return
+	}
+
+	name, err := s.resolve(name)
+	if err != nil {
+		s.writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest, err.Error())
+		return
+	}
+
+	file, data, ok := s.lookup(name)
+	if !ok {
+		s.writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound, "the server could not find the requested resource")
+		return
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(file))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		w.Write(data)
+	}
+}
+
+// resolve normalizes the MAC addresses in the paths of lookup
+// files and boot scripts to the format of the rendered artifacts.
+func (s *Server) resolve(name string) (string, error) {
+	// MAC addresses are accepted in any format and normalized,
+	// because clients usually use the format of their platform.
+	if mac, ok := strings.CutPrefix(name, "by-mac/"); ok {
+		parsed, err := cloud.ParseMAC(strings.TrimSuffix(mac, ".json"))
+		if err != nil {
+			return "", err
+		}
+
+		name = config.MACLookupFile(parsed)
+	}
+
+	// Boot scripts are also looked up by MAC address. Unknown machines
+	// receive the fallback script instead of an error, because iPXE can
+	// not display the error to the user of the machine.
+	if mac, ok := strings.CutPrefix(name, "boot/"); ok && path.Ext(mac) == ".ipxe" {
+		if parsed, err := cloud.ParseMAC(strings.TrimSuffix(mac, ".ipxe")); err == nil {
+			name = config.BootScriptFile(parsed)
+
+			if _, _, ok := s.lookup(name); !ok {
+				name = config.BootScriptFileUnknown
+			}
+		}
+	}
+
+	return name, nil
+}
+
+// serveList serves the resources of a list endpoint that match the selectors.
+func (s *Server) serveList(w http.ResponseWriter, r *http.Request, name string, labelSelector string, fieldSelector string) {
+	schema, ok := config.LookupSchema(strings.TrimSuffix(name, "/index.json"))
+	if !ok {
+		s.writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest, "selectors are only supported on list endpoints")
+		return
+	}
+
+	labels, err := config.ParseLabelSelector(labelSelector)
+	if err != nil {
+		s.writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest, err.Error())
+		return
+	}
+
+	fields, err := config.ParseFieldSelector(fieldSelector)
+	if err != nil {
+		s.writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest, err.Error())
+		return
+	}
+
+	s.mutex.RLock()
+	list, err := schema.Filter(s.repository, labels, fields)
+	s.mutex.RUnlock()
+	if err != nil {
+		s.writeStatus(w, http.StatusInternalServerError, metav1.StatusReasonInternalError, err.Error())
+		return
+	}
+
+	data, err := config.Encode(list)
+	if err != nil {
+		s.writeStatus(w, http.StatusInternalServerError, metav1.StatusReasonInternalError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		w.Write(data)
+	}
+}
+
+// ReadFile returns the rendered artifact for a path relative to the
+// version directory, e.g. boot/32-de-fa-97-71-4f.ipxe. Missing
+// artifacts result in an error that wraps fs.ErrNotExist.
+func (s *Server) ReadFile(name string) ([]byte, error) {
+	name, err := s.resolve(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+
+	_, data, ok := s.lookup(name)
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+
+	return data, nil
+}
+
+// lookup returns the artifact for a path relative to the version directory.
+func (s *Server) lookup(name string) (string, []byte, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	candidates := []string{name}
+	if path.Ext(name) == "" {
+		candidates = append(candidates, name+".json", path.Join(name, "index.json"))
+	}
+
+	for _, candidate := range candidates {
+		if data, ok := s.artifacts[candidate]; ok {
+			return candidate, data, true
+		}
+	}
+
+	return "", nil, false
+}
+
+// writeStatus writes an error as Kubernetes Status object.
+func (s *Server) writeStatus(w http.ResponseWriter, code int, reason metav1.StatusReason, message string) {
+	status := metav1.Status{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Status",
+			APIVersion: "v1",
+		},
+		Status:  metav1.StatusFailure,
+		Message: message,
+		Reason:  reason,
+		Code:    int32(code),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		s.logger.Error("Failed to write status", "error", err)
+	}
+}
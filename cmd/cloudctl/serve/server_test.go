@@ -0,0 +1,262 @@
+package serve
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+)
+
+// newTestServer copies the manifests in testdata to a temporary
+// directory, so that they can be changed, and loads them.
+func newTestServer() (*Server, string) {
+	srcDir := GinkgoT().TempDir()
+	Expect(os.CopyFS(srcDir, os.DirFS(path.Join("testdata", "manifests")))).To(Succeed())
+
+	server := NewServer(srcDir, path.Join("testdata", "config.yaml"), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	Expect(server.Reload()).To(Succeed())
+
+	return server, srcDir
+}
+
+// get sends a GET request to the server and returns the response.
+func get(server *Server, target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	return recorder
+}
+
+// decode decodes the body of a response.
+func decode[T any](recorder *httptest.ResponseRecorder) *T {
+	value := new(T)
+	Expect(json.Unmarshal(recorder.Body.Bytes(), value)).To(Succeed())
+	return value
+}
+
+var _ = Describe("Server", func() {
+	var (
+		server *Server
+		srcDir string
+	)
+
+	BeforeEach(func() {
+		server, srcDir = newTestServer()
+	})
+
+	It("should serve the list of machines", func() {
+		response := get(server, "/v1beta1/machines")
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(response.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		list := decode[cloud.MachineList](response)
+		Expect(list.Items).To(HaveLen(2))
+		Expect(list.Items[0].Name).To(Equal("ant"))
+		Expect(list.Items[1].Name).To(Equal("bee"))
+	})
+
+	It("should serve a single machine with its hardware profile", func() {
+		response := get(server, "/v1beta1/machines/ant")
+		Expect(response.Code).To(Equal(http.StatusOK))
+
+		machine := decode[cloud.Machine](response)
+		Expect(machine.Name).To(Equal("ant"))
+		Expect(machine.Spec.Hardware.CPU.Architecture).To(Equal(cloud.ArchitectureARM64))
+		Expect(machine.Status.Phase).To(Equal(cloud.MachinePhaseRegistered))
+	})
+
+	DescribeTable("should respond with a Status",
+		func(method string, target string, code int, reason metav1.StatusReason) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(method, target, nil))
+			Expect(response.Code).To(Equal(code))
+			Expect(response.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			status := decode[metav1.Status](response)
+			Expect(status.Kind).To(Equal("Status"))
+			Expect(status.APIVersion).To(Equal("v1"))
+			Expect(status.Status).To(Equal(metav1.StatusFailure))
+			Expect(status.Code).To(BeEquivalentTo(code))
+			Expect(status.Reason).To(Equal(reason))
+		},
+		Entry("for an unknown machine", http.MethodGet, "/v1beta1/machines/cow", http.StatusNotFound, metav1.StatusReasonNotFound),
+		Entry("for another version", http.MethodGet, "/v1alpha1/machines", http.StatusNotFound, metav1.StatusReasonNotFound),
+		Entry("for other methods", http.MethodPost, "/v1beta1/machines", http.StatusMethodNotAllowed, metav1.StatusReasonMethodNotAllowed),
+	)
+
+	It("should keep the previous artifacts if the manifests become invalid", func() {
+		Expect(os.WriteFile(path.Join(srcDir, "machines", "cow.yaml"), []byte("kind: [\n"), 0644)).To(Succeed())
+
+		Expect(server.Reload()).NotTo(Succeed())
+		Expect(get(server, "/v1beta1/machines/ant").Code).To(Equal(http.StatusOK))
+		Expect(get(server, "/v1beta1/machines/cow").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should reload the manifests when they change", func() {
+		done := make(chan struct{})
+		watching := make(chan error, 1)
+		go func() {
+			watching <- server.Watch(done)
+		}()
+		DeferCleanup(func() {
+			close(done)
+			Eventually(watching).Should(Receive(BeNil()))
+		})
+
+		// Give the watcher time to add the directories.
+		time.Sleep(100 * time.Millisecond)
+
+		bee, err := os.ReadFile(path.Join(srcDir, "machines", "bee.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		cow := strings.NewReplacer("bee", "cow", "02:00:00:00:00:02", "02:00:00:00:00:03").Replace(string(bee))
+		Expect(os.WriteFile(path.Join(srcDir, "machines", "cow.yaml"), []byte(cow), 0644)).To(Succeed())
+
+		Eventually(func() int {
+			return get(server, "/v1beta1/machines/cow").Code
+		}).WithTimeout(5 * time.Second).Should(Equal(http.StatusOK))
+
+		Expect(os.WriteFile(path.Join(srcDir, "machines", "cow.yaml"), []byte("kind: [\n"), 0644)).To(Succeed())
+
+		Consistently(func() int {
+			return get(server, "/v1beta1/machines/cow").Code
+		}).WithTimeout(4 * reloadDelay).Should(Equal(http.StatusOK))
+	})
+})
+
+var _ = Describe("Selectors", func() {
+	var server *Server
+
+	BeforeEach(func() {
+		server, _ = newTestServer()
+	})
+
+	// names returns the names of the machines of a response.
+	names := func(response *httptest.ResponseRecorder) []string {
+		list := decode[cloud.MachineList](response)
+
+		names := make([]string, 0, len(list.Items))
+		for _, machine := range list.Items {
+			names = append(names, machine.Name)
+		}
+
+		return names
+	}
+
+	DescribeTable("should filter list endpoints",
+		func(query string, expected ...string) {
+			response := get(server, "/v1beta1/machines?"+query)
+			Expect(response.Code).To(Equal(http.StatusOK))
+			Expect(names(response)).To(Equal(expected))
+		},
+		Entry("by label", "labelSelector=topology.kubernetes.io/zone%3Dhome", "ant"),
+		Entry("by set-based label requirement", "labelSelector=topology.kubernetes.io/zone+in+(home,office)", "ant", "bee"),
+		Entry("by missing label", "labelSelector=!cloud.nicklasfrahm.dev/machinepool", "bee"),
+		Entry("by name", "fieldSelector=metadata.name%3Dbee", "bee"),
+		Entry("by label and name", "labelSelector=topology.kubernetes.io/zone&fieldSelector=metadata.name!%3Dant", "bee"),
+		Entry("without matches", "labelSelector=topology.kubernetes.io/zone%3Dgarage"),
+	)
+
+	DescribeTable("should reject invalid selectors",
+		func(target string, message string) {
+			response := get(server, target)
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+
+			status := decode[metav1.Status](response)
+			Expect(status.Reason).To(Equal(metav1.StatusReasonBadRequest))
+			Expect(status.Message).To(ContainSubstring(message))
+		},
+		Entry("with invalid label selector", "/v1beta1/machines?labelSelector=zone%3D%3D%3D", "invalid selector"),
+		Entry("with unsupported field", "/v1beta1/machines?fieldSelector=spec.hardware.model%3DNUC", "field label not supported: spec.hardware.model"),
+		Entry("on a single resource", "/v1beta1/machines/ant?labelSelector=zone", "only supported on list endpoints"),
+		Entry("on a boot script", "/v1beta1/boot/index.ipxe?fieldSelector=metadata.name%3Dant", "only supported on list endpoints"),
+	)
+})
+
+var _ = Describe("MAC address lookup", func() {
+	var server *Server
+
+	BeforeEach(func() {
+		server, _ = newTestServer()
+	})
+
+	DescribeTable("should normalize the MAC address",
+		func(target string) {
+			response := get(server, target)
+			Expect(response.Code).To(Equal(http.StatusOK))
+			Expect(decode[cloud.Machine](response).Name).To(Equal("ant"))
+		},
+		Entry("with colons", "/v1beta1/by-mac/32:de:fa:97:71:4f"),
+		Entry("with dashes", "/v1beta1/by-mac/32-de-fa-97-71-4f"),
+		Entry("in uppercase", "/v1beta1/by-mac/32-DE-FA-97-71-4F"),
+		Entry("with file extension", "/v1beta1/by-mac/32:DE:FA:97:71:4F.json"),
+	)
+
+	It("should not find unknown MAC addresses", func() {
+		Expect(get(server, "/v1beta1/by-mac/02-00-00-00-00-99").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should reject invalid MAC addresses", func() {
+		response := get(server, "/v1beta1/by-mac/ant")
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+		Expect(decode[metav1.Status](response).Reason).To(Equal(metav1.StatusReasonBadRequest))
+	})
+})
+
+var _ = Describe("Boot scripts", func() {
+	var server *Server
+
+	BeforeEach(func() {
+		server, _ = newTestServer()
+	})
+
+	// expectScript expects the response to be the script in testdata/boot.
+	expectScript := func(response *httptest.ResponseRecorder, name string) {
+		script, err := os.ReadFile(path.Join("testdata", "boot", name))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(response.Header().Get("Content-Type")).To(Equal("text/plain; charset=utf-8"))
+		Expect(response.Body.String()).To(Equal(string(script)))
+	}
+
+	DescribeTable("should serve the script of the machine",
+		func(target string, name string) {
+			expectScript(get(server, target), name)
+		},
+		Entry("with dashes", "/v1beta1/boot/32-de-fa-97-71-4f.ipxe", "ant.ipxe"),
+		Entry("with colons", "/v1beta1/boot/32:de:fa:97:71:4f.ipxe", "ant.ipxe"),
+		Entry("in uppercase", "/v1beta1/boot/32-DE-FA-97-71-4F.ipxe", "ant.ipxe"),
+		Entry("without hardware profile", "/v1beta1/boot/02-00-00-00-00-02.ipxe", "bee.ipxe"),
+	)
+
+	It("should serve the fallback script to unknown machines", func() {
+		response := get(server, "/v1beta1/boot/02-00-00-00-00-99.ipxe")
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(response.Body.String()).To(ContainSubstring("is not in the inventory"))
+		Expect(response.Body.String()).To(Equal(get(server, "/v1beta1/boot/unknown.ipxe").Body.String()))
+	})
+
+	It("should serve the index script that chains the script of the machine", func() {
+		response := get(server, "/v1beta1/boot/index.ipxe")
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(response.Body.String()).To(ContainSubstring("chain --autofree ./${netX/mac}.ipxe || chain --autofree ./unknown.ipxe"))
+	})
+
+	It("should serve the scripts via TFTP", func() {
+		handler := tftpHandler(server, "")
+
+		data, err := handler.ReadFile("/boot/32-DE-FA-97-71-4F.ipxe")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("on ant (arm64)"))
+	})
+})
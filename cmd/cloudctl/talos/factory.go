@@ -0,0 +1,65 @@
+package talos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+)
+
+// FactoryInstaller is the installer image of the Talos Image Factory
+// without schematic ID, which is used for hardware with an overlay.
+const FactoryInstaller = "factory.talos.dev/installer"
+
+// Schematic is the schematic of an image of the Talos Image Factory.
+// The order and the tags of the fields must match the upstream type,
+// as the schematic ID is derived from its YAML representation.
+// Reference: https://github.com/siderolabs/image-factory/blob/main/pkg/schematic/schematic.go
+type Schematic struct {
+	Overlay       SchematicOverlay       `yaml:"overlay,omitempty"`
+	Customization SchematicCustomization `yaml:"customization"`
+}
+
+// SchematicOverlay is the overlay of a schematic.
+type SchematicOverlay struct {
+	Image string `yaml:"image,omitempty"`
+	Name  string `yaml:"name,omitempty"`
+}
+
+// SchematicCustomization contains the customizations of a schematic,
+// none of which are generated from the inventory.
+type SchematicCustomization struct{}
+
+// ID returns the ID of the schematic, which is the SHA-256 hash of its
+// YAML representation as computed by the Image Factory.
+func (s *Schematic) ID() (string, error) {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal schematic: %w", err)
+	}
+
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// overlayInstaller returns the installer image of the Image
+// Factory without tag that includes the overlay.
+func overlayInstaller(overlay *cloud.TalosOverlay) (string, error) {
+	schematic := &Schematic{
+		Overlay: SchematicOverlay{
+			Image: overlay.Image,
+			Name:  overlay.Name,
+		},
+	}
+
+	id, err := schematic.ID()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/%s", FactoryInstaller, id), nil
+}
@@ -0,0 +1,192 @@
+package talos
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/config"
+)
+
+// GenConfigCommand returns the gen-config command.
+func GenConfigCommand() *cobra.Command {
+	var manifestsDir string
+	var secretsFile string
+	var settingsFile string
+	var outputDir string
+	var installDisk string
+
+	cmd := &cobra.Command{
+		Use:   "gen-config <region>",
+		Short: "Generate machine configuration for a region",
+		Long: `Generate machine configuration for a region.
+
+A machine configuration is generated for every control plane and
+worker of the region. The configuration is written to a file named
+after the machine in the output directory. If a patch exists for a
+machine in the talos directory of the manifests, e.g. talos/ant.yaml,
+it is merged into the generated configuration. Maps are merged
+recursively, while lists are replaced.
+
+Talos is installed to the disk of a machine with the role install.
+Disks without a path in /dev/disk/by-id are selected by their serial
+number, type and size. The install disk of the flags is only used
+for machines that do not describe their disks.
+
+If the region has no endpoint, the Kubernetes API of its first
+control plane is used, which is addressed by its first static or
+observed address or by its name.
+
+Hardware that requires an overlay, e.g. single board computers,
+must specify an installer that includes the overlay.
+
+The secrets bundle has the format of "talosctl gen secrets".`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("expected exactly one argument")
+			}
+
+			repository, err := config.LoadConfigRepository(manifestsDir)
+			if err != nil {
+				return err
+			}
+
+			if err := repository.ResolveHardwareProfiles(); err != nil {
+				return fmt.Errorf("failed to resolve hardware profiles: %w", err)
+			}
+
+			settings, err := config.LoadSettings(settingsFile)
+			if err != nil {
+				return err
+			}
+
+			secrets, err := LoadSecretsBundle(secretsFile)
+			if err != nil {
+				return err
+			}
+
+			region, err := findRegion(repository, args[0])
+			if err != nil {
+				return err
+			}
+
+			if region.Spec.Baremetal == nil {
+				return fmt.Errorf("region %s is not a baremetal region", region.Name)
+			}
+
+			endpoint := region.Spec.Baremetal.Endpoint
+			if endpoint == "" {
+				if endpoint, err = controlPlaneEndpoint(repository, region); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(os.Stderr, "🟡 Region %s has no endpoint, using the first control plane: %s\n", region.Name, endpoint)
+			}
+
+			roles := map[MachineType][]cloud.MachineReference{
+				MachineTypeControlPlane: region.Spec.Baremetal.Controlplanes,
+				MachineTypeWorker:       region.Spec.Baremetal.Workers,
+			}
+
+			if err := os.MkdirAll(outputDir, 0755); err != nil {
+				return fmt.Errorf("failed to create output directory: %w", err)
+			}
+
+			for _, machineType := range []MachineType{MachineTypeControlPlane, MachineTypeWorker} {
+				for _, ref := range roles[machineType] {
+					machine, err := findMachine(repository, ref.Name)
+					if err != nil {
+						return err
+					}
+
+					patch, err := LoadPatch(manifestsDir, machine.Name)
+					if err != nil {
+						return err
+					}
+
+					data, err := Generate(Input{
+						Region:      region,
+						Machine:     machine,
+						Type:        machineType,
+						Endpoint:    endpoint,
+						Secrets:     secrets,
+						Version:     settings.Talos.Version,
+						InstallDisk: installDisk,
+						Patch:       patch,
+					})
+					if err != nil {
+						return fmt.Errorf("failed to generate configuration for machine %s: %w", machine.Name, err)
+					}
+
+					// The configuration contains secrets, so it must only be readable by the owner.
+					file := path.Join(outputDir, machine.Name+".yaml")
+					if err := os.WriteFile(file, data, 0600); err != nil {
+						return fmt.Errorf("failed to write configuration: %w", err)
+					}
+
+					fmt.Printf("🟢 Generated %s configuration: %s\n", machineType, file)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&manifestsDir, "manifests", "deploy/manifests", "directory containing the manifests")
+	cmd.Flags().StringVar(&secretsFile, "secrets", "secrets.yaml", "secrets bundle of the region")
+	cmd.Flags().StringVar(&settingsFile, "config", config.DefaultSettingsFile, "settings file that pins the version of Talos")
+	cmd.Flags().StringVarP(&outputDir, "output", "o", ".", "directory to write the machine configurations to")
+	cmd.Flags().StringVar(&installDisk, "install-disk", "/dev/sda", "disk to install Talos to if a machine does not describe an install disk")
+
+	return cmd
+}
+
+// controlPlaneEndpoint returns the URL of the Kubernetes API of the first
+// control plane of the region. The control plane is addressed by its first
+// static or observed address or by its name if it has no address.
+func controlPlaneEndpoint(repository *config.ConfigRepository, region *cloud.Region) (string, error) {
+	controlplanes := region.Spec.Baremetal.Controlplanes
+	if len(controlplanes) == 0 {
+		return "", fmt.Errorf("region %s has neither an endpoint nor a control plane", region.Name)
+	}
+
+	machine, err := findMachine(repository, controlplanes[0].Name)
+	if err != nil {
+		return "", err
+	}
+
+	host := machine.Name
+	if addresses := config.MachineAddresses(machine); len(addresses) > 0 {
+		host = addresses[0].String()
+	}
+
+	return "https://" + net.JoinHostPort(host, strconv.Itoa(DefaultAPIServerPort)), nil
+}
+
+// findRegion returns the region with the given name.
+func findRegion(repository *config.ConfigRepository, name string) (*cloud.Region, error) {
+	for index := range repository.Regions.Items {
+		if region := &repository.Regions.Items[index]; region.Name == name {
+			return region, nil
+		}
+	}
+
+	return nil, fmt.Errorf("region not found: %s", name)
+}
+
+// findMachine returns the machine with the given name.
+func findMachine(repository *config.ConfigRepository, name string) (*cloud.Machine, error) {
+	for index := range repository.Machines.Items {
+		if machine := &repository.Machines.Items[index]; machine.Name == name {
+			return machine, nil
+		}
+	}
+
+	return nil, fmt.Errorf("machine not found: %s", name)
+}
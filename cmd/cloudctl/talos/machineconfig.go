@@ -0,0 +1,496 @@
+package talos
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"net/url"
+
+	"github.com/siderolabs/talos/pkg/machinery/config/configloader"
+	"sigs.k8s.io/yaml"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+)
+
+// The types below implement the subset of the v1alpha1 machine
+// configuration of Talos that is generated from the inventory.
+// Everything else can be configured with per-machine patches.
+// The generated configuration is validated with the types of the
+// Talos machinery, so that the types cannot drift from upstream.
+// Reference: https://www.talos.dev/latest/reference/configuration/v1alpha1/config/
+
+// MachineType is the role of a machine in a cluster.
+type MachineType string
+
+const (
+	// MachineTypeControlPlane runs the control plane of the cluster.
+	MachineTypeControlPlane MachineType = "controlplane"
+	// MachineTypeWorker only runs workloads.
+	MachineTypeWorker MachineType = "worker"
+)
+
+// DefaultInstaller is the installer image that is used if
+// the hardware of a machine does not specify an installer.
+const DefaultInstaller = "ghcr.io/siderolabs/installer"
+
+// DefaultAPIServerPort is the port of the Kubernetes API on the control planes.
+const DefaultAPIServerPort = 6443
+
+const (
+	// DefaultPodSubnet is the network of the pods if the Region does not specify one.
+	DefaultPodSubnet = "10.244.0.0/16"
+	// DefaultServiceSubnet is the network of the services if the Region does not specify one.
+	DefaultServiceSubnet = "10.96.0.0/12"
+	// DefaultDNSDomain is the domain of the services if the Region does not specify one.
+	DefaultDNSDomain = "cluster.local"
+)
+
+// Config is the machine configuration of Talos.
+type Config struct {
+	Version string        `json:"version"`
+	Debug   bool          `json:"debug"`
+	Persist bool          `json:"persist"`
+	Machine MachineConfig `json:"machine"`
+	Cluster ClusterConfig `json:"cluster"`
+}
+
+// MachineConfig configures the machine itself.
+type MachineConfig struct {
+	Type    MachineType       `json:"type"`
+	Token   string            `json:"token"`
+	CA      CertificateAndKey `json:"ca"`
+	Network NetworkConfig     `json:"network"`
+	Install InstallConfig     `json:"install"`
+}
+
+// NetworkConfig configures the network of a machine.
+type NetworkConfig struct {
+	Hostname   string   `json:"hostname,omitempty"`
+	Interfaces []Device `json:"interfaces,omitempty"`
+}
+
+// Device configures a network interface.
+type Device struct {
+	Interface      string          `json:"interface,omitempty"`
+	DeviceSelector *DeviceSelector `json:"deviceSelector,omitempty"`
+	Addresses      []string        `json:"addresses,omitempty"`
+	Routes         []Route         `json:"routes,omitempty"`
+	Bond           *Bond           `json:"bond,omitempty"`
+	Bridge         *Bridge         `json:"bridge,omitempty"`
+	VLANs          []VLAN          `json:"vlans,omitempty"`
+	MTU            int32           `json:"mtu,omitempty"`
+	DHCP           bool            `json:"dhcp,omitempty"`
+}
+
+// Route configures a route of a network interface.
+type Route struct {
+	Network string `json:"network"`
+	Gateway string `json:"gateway"`
+}
+
+// Bond configures a network interface as bond of other interfaces.
+type Bond struct {
+	DeviceSelectors []DeviceSelector `json:"deviceSelectors"`
+	Mode            string           `json:"mode,omitempty"`
+}
+
+// Bridge configures a network interface as bridge of other interfaces.
+type Bridge struct {
+	Interfaces []string `json:"interfaces"`
+	STP        *STP     `json:"stp,omitempty"`
+}
+
+// STP configures the spanning tree protocol of a bridge.
+type STP struct {
+	Enabled bool `json:"enabled"`
+}
+
+// VLAN configures a VLAN on top of a network interface.
+type VLAN struct {
+	VLANID    int32    `json:"vlanId"`
+	Addresses []string `json:"addresses,omitempty"`
+	Routes    []Route  `json:"routes,omitempty"`
+	MTU       int32    `json:"mtu,omitempty"`
+	DHCP      bool     `json:"dhcp,omitempty"`
+}
+
+// DeviceSelector selects a network interface by its properties.
+type DeviceSelector struct {
+	HardwareAddr string `json:"hardwareAddr,omitempty"`
+}
+
+// InstallConfig configures the installation of Talos.
+type InstallConfig struct {
+	Disk            string               `json:"disk,omitempty"`
+	DiskSelector    *InstallDiskSelector `json:"diskSelector,omitempty"`
+	Image           string               `json:"image"`
+	Wipe            bool                 `json:"wipe"`
+	ExtraKernelArgs []string             `json:"extraKernelArgs,omitempty"`
+}
+
+// InstallDiskSelector selects the install disk by its properties.
+type InstallDiskSelector struct {
+	Size   string `json:"size,omitempty"`
+	Serial string `json:"serial,omitempty"`
+	Type   string `json:"type,omitempty"`
+}
+
+// ClusterConfig configures the cluster that the machine is part of.
+type ClusterConfig struct {
+	ID                        string               `json:"id"`
+	Secret                    string               `json:"secret"`
+	ControlPlane              ControlPlaneConfig   `json:"controlPlane"`
+	ClusterName               string               `json:"clusterName"`
+	Network                   ClusterNetworkConfig `json:"network"`
+	Token                     string               `json:"token"`
+	SecretboxEncryptionSecret string               `json:"secretboxEncryptionSecret,omitempty"`
+	CA                        CertificateAndKey    `json:"ca"`
+	AggregatorCA              *CertificateAndKey   `json:"aggregatorCA,omitempty"`
+	ServiceAccount            *Key                 `json:"serviceAccount,omitempty"`
+	Etcd                      *EtcdConfig          `json:"etcd,omitempty"`
+}
+
+// ControlPlaneConfig configures the endpoint of the control plane.
+type ControlPlaneConfig struct {
+	Endpoint string `json:"endpoint"`
+}
+
+// ClusterNetworkConfig configures the network of the cluster.
+type ClusterNetworkConfig struct {
+	DNSDomain      string   `json:"dnsDomain"`
+	PodSubnets     []string `json:"podSubnets"`
+	ServiceSubnets []string `json:"serviceSubnets"`
+}
+
+// EtcdConfig configures etcd.
+type EtcdConfig struct {
+	CA CertificateAndKey `json:"ca"`
+}
+
+// Input contains everything that is needed to generate
+// the machine configuration of a single machine.
+type Input struct {
+	// Region is the region that the machine is part of.
+	Region *cloud.Region
+	// Machine is the machine with its hardware merged with its HardwareProfile.
+	Machine *cloud.Machine
+	// Type is the role of the machine in the region.
+	Type MachineType
+	// Endpoint is the URL of the Kubernetes API. Defaults to the endpoint of the region.
+	Endpoint string
+	// Secrets is the secrets bundle of the region.
+	Secrets *SecretsBundle
+	// Version is the version of Talos.
+	Version string
+	// InstallDisk is the disk that Talos is installed to
+	// if the machine does not describe an install disk.
+	InstallDisk string
+	// Patch is merged into the generated configuration.
+	Patch map[string]any
+}
+
+// Generate generates the machine configuration of a machine as YAML.
+func Generate(input Input) ([]byte, error) {
+	baremetal := input.Region.Spec.Baremetal
+	if baremetal == nil {
+		return nil, fmt.Errorf("region %s is not a baremetal region", input.Region.Name)
+	}
+
+	endpoint := input.Endpoint
+	if endpoint == "" {
+		endpoint = baremetal.Endpoint
+	}
+
+	if endpoint == "" {
+		return nil, fmt.Errorf("region %s has no endpoint", input.Region.Name)
+	}
+
+	if _, err := url.Parse(endpoint); err != nil {
+		return nil, fmt.Errorf("region %s has an invalid endpoint: %w", input.Region.Name, err)
+	}
+
+	if input.Version == "" {
+		return nil, fmt.Errorf("version of Talos is required")
+	}
+
+	secrets := input.Secrets
+	hardware := input.Machine.Spec.Hardware
+
+	installer, err := installerImage(hardware.Talos)
+	if err != nil {
+		return nil, err
+	}
+
+	var kernelArgs []string
+	if hardware.Talos != nil {
+		kernelArgs = hardware.Talos.KernelArgs
+	}
+
+	disk, diskSelector := installDisk(input)
+
+	interfaces := networkDevices(&input.Machine.Spec)
+
+	config := &Config{
+		Version: "v1alpha1",
+		Persist: true,
+		Machine: MachineConfig{
+			Type:  input.Type,
+			Token: secrets.TrustdInfo.Token,
+			CA: CertificateAndKey{
+				Crt: secrets.Certs.OS.Crt,
+			},
+			Network: NetworkConfig{
+				Hostname:   input.Machine.Name,
+				Interfaces: interfaces,
+			},
+			Install: InstallConfig{
+				Disk:            disk,
+				DiskSelector:    diskSelector,
+				Image:           fmt.Sprintf("%s:%s", installer, input.Version),
+				ExtraKernelArgs: kernelArgs,
+			},
+		},
+		Cluster: ClusterConfig{
+			ID:     secrets.Cluster.ID,
+			Secret: secrets.Cluster.Secret,
+			ControlPlane: ControlPlaneConfig{
+				Endpoint: endpoint,
+			},
+			ClusterName: input.Region.Name,
+			Network:     clusterNetwork(baremetal.Network),
+			Token:       secrets.Secrets.BootstrapToken,
+			CA: CertificateAndKey{
+				Crt: secrets.Certs.K8s.Crt,
+			},
+		},
+	}
+
+	// Only control planes receive the private keys of the certificate authorities.
+	if input.Type == MachineTypeControlPlane {
+		config.Machine.CA.Key = secrets.Certs.OS.Key
+		config.Cluster.CA.Key = secrets.Certs.K8s.Key
+		config.Cluster.SecretboxEncryptionSecret = secrets.Secrets.SecretboxEncryptionSecret
+		config.Cluster.AggregatorCA = &secrets.Certs.K8sAggregator
+		config.Cluster.ServiceAccount = &secrets.Certs.K8sServiceAccount
+		config.Cluster.Etcd = &EtcdConfig{CA: secrets.Certs.Etcd}
+	}
+
+	return render(config, input.Patch)
+}
+
+// clusterNetwork returns the network of the cluster
+// with the defaults for all unspecified values.
+func clusterNetwork(network *cloud.ClusterNetwork) ClusterNetworkConfig {
+	config := ClusterNetworkConfig{
+		DNSDomain:      DefaultDNSDomain,
+		PodSubnets:     []string{DefaultPodSubnet},
+		ServiceSubnets: []string{DefaultServiceSubnet},
+	}
+
+	if network == nil {
+		return config
+	}
+
+	if network.DNSDomain != "" {
+		config.DNSDomain = network.DNSDomain
+	}
+
+	if len(network.PodSubnets) > 0 {
+		config.PodSubnets = network.PodSubnets
+	}
+
+	if len(network.ServiceSubnets) > 0 {
+		config.ServiceSubnets = network.ServiceSubnets
+	}
+
+	return config
+}
+
+// networkDevices returns the network configuration of the interfaces, bonds
+// and bridges of the machine. Interfaces are selected by their MAC address,
+// as their names depend on the hardware, except for members of bridges,
+// which can only be referenced by name. Members of bonds and bridges are
+// configured by them. Links without static addresses use DHCP.
+func networkDevices(spec *cloud.MachineSpec) []Device {
+	members := make(map[string]bool)
+	for _, bond := range spec.Bonds {
+		for _, name := range bond.Interfaces {
+			members[name] = true
+		}
+	}
+	for _, bridge := range spec.Bridges {
+		for _, name := range bridge.Interfaces {
+			members[name] = true
+		}
+	}
+
+	devices := make([]Device, 0, len(spec.Interfaces)+len(spec.Bonds)+len(spec.Bridges))
+	for _, iface := range spec.Interfaces {
+		if iface.Name != "" && members[iface.Name] {
+			continue
+		}
+
+		device := linkDevice(iface.LinkConfig, iface.VLANs, false)
+		device.DeviceSelector = &DeviceSelector{HardwareAddr: iface.MAC.String()}
+		devices = append(devices, device)
+	}
+
+	for _, bond := range spec.Bonds {
+		selectors := make([]DeviceSelector, 0, len(bond.Interfaces))
+		for _, name := range bond.Interfaces {
+			for _, iface := range spec.Interfaces {
+				if iface.Name == name {
+					selectors = append(selectors, DeviceSelector{HardwareAddr: iface.MAC.String()})
+				}
+			}
+		}
+
+		device := linkDevice(bond.LinkConfig, bond.VLANs, members[bond.Name])
+		device.Interface = bond.Name
+		device.Bond = &Bond{DeviceSelectors: selectors, Mode: string(bond.Mode)}
+		devices = append(devices, device)
+	}
+
+	for _, bridge := range spec.Bridges {
+		device := linkDevice(bridge.LinkConfig, bridge.VLANs, false)
+		device.Interface = bridge.Name
+		device.Bridge = &Bridge{Interfaces: bridge.Interfaces}
+		if bridge.STP {
+			device.Bridge.STP = &STP{Enabled: true}
+		}
+		devices = append(devices, device)
+	}
+
+	return devices
+}
+
+// linkDevice returns the addresses, the default route, the MTU and the VLANs
+// of a link. Members of bonds and bridges never use DHCP themselves.
+func linkDevice(config cloud.LinkConfig, vlans []cloud.VLAN, member bool) Device {
+	device := Device{
+		Addresses: config.Addresses,
+		Routes:    defaultRoutes(config.Gateway),
+		MTU:       config.MTU,
+		DHCP:      !member && len(config.Addresses) == 0,
+	}
+
+	for _, vlan := range vlans {
+		device.VLANs = append(device.VLANs, VLAN{
+			VLANID:    vlan.ID,
+			Addresses: vlan.Addresses,
+			Routes:    defaultRoutes(vlan.Gateway),
+			MTU:       vlan.MTU,
+			DHCP:      len(vlan.Addresses) == 0,
+		})
+	}
+
+	return device
+}
+
+// defaultRoutes returns the default route via the gateway, if any.
+func defaultRoutes(gateway string) []Route {
+	addr, err := netip.ParseAddr(gateway)
+	if err != nil {
+		return nil
+	}
+
+	network := "0.0.0.0/0"
+	if addr.Unmap().Is6() {
+		network = "::/0"
+	}
+
+	return []Route{{Network: network, Gateway: addr.String()}}
+}
+
+// installerImage returns the installer image of the hardware without tag.
+// The default installer does not contain any overlay, so hardware that
+// requires an overlay uses the installer of the Talos Image Factory for
+// the schematic of the overlay, unless it specifies an installer.
+func installerImage(talos *cloud.HardwareTalos) (string, error) {
+	if talos == nil {
+		return DefaultInstaller, nil
+	}
+
+	if talos.Installer != "" {
+		return talos.Installer, nil
+	}
+
+	if talos.Overlay != nil {
+		return overlayInstaller(talos.Overlay)
+	}
+
+	return DefaultInstaller, nil
+}
+
+// installDisk returns the path or the selector of the install disk of the
+// machine. Disks without path are selected by their serial number, type and
+// size, where the size is a lower bound, as the actual capacity of a disk
+// usually exceeds its nominal capacity.
+func installDisk(input Input) (string, *InstallDiskSelector) {
+	disk := input.Machine.Spec.InstallDisk()
+	if disk == nil {
+		return input.InstallDisk, nil
+	}
+
+	if disk.ByID != "" {
+		return disk.ByID, nil
+	}
+
+	selector := &InstallDiskSelector{
+		Serial: disk.Serial,
+		Type:   string(disk.Type),
+	}
+	if disk.Size != nil {
+		selector.Size = ">= " + disk.Size.String()
+	}
+
+	return "", selector
+}
+
+// render merges the patch into the configuration and encodes it as YAML.
+func render(config *Config, patch map[string]any) ([]byte, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode machine configuration: %w", err)
+	}
+
+	document := make(map[string]any)
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to decode machine configuration: %w", err)
+	}
+
+	data, err = yaml.Marshal(Merge(document, patch))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode machine configuration: %w", err)
+	}
+
+	if err := validate(data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// metal is the runtime mode of Talos on physical machines.
+type metal struct{}
+
+func (metal) String() string        { return "metal" }
+func (metal) RequiresInstall() bool { return true }
+func (metal) InContainer() bool     { return false }
+
+// validate decodes the machine configuration with the types of the Talos
+// machinery, which rejects unknown fields, e.g. fields that were renamed
+// upstream or misspelled in a patch, and validates it. Warnings about
+// deprecated or ignored fields are not considered errors.
+func validate(data []byte) error {
+	provider, err := configloader.NewFromBytes(data)
+	if err != nil {
+		return fmt.Errorf("invalid machine configuration: %w", err)
+	}
+
+	if _, err := provider.Validate(metal{}); err != nil {
+		return fmt.Errorf("invalid machine configuration: %w", err)
+	}
+
+	return nil
+}
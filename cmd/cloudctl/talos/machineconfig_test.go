@@ -0,0 +1,288 @@
+package talos
+
+import (
+	"flag"
+	"os"
+	"path"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cloud "github.com/nicklasfrahm/cloud/api/v1beta1"
+)
+
+// update regenerates the golden files instead of comparing with them.
+var update = flag.Bool("update", false, "update the golden files")
+
+// expectGolden compares the data with the golden file in testdata.
+func expectGolden(name string, data []byte) {
+	file := path.Join("testdata", name)
+	if *update {
+		Expect(os.WriteFile(file, data, 0644)).To(Succeed())
+	}
+
+	golden, err := os.ReadFile(file)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(string(data)).To(Equal(string(golden)))
+}
+
+// mustMAC parses a MAC address.
+func mustMAC(value string) cloud.MAC {
+	mac, err := cloud.ParseMAC(value)
+	Expect(err).NotTo(HaveOccurred())
+	return mac
+}
+
+var _ = Describe("Generate", func() {
+	var input Input
+
+	BeforeEach(func() {
+		size := resource.MustParse("1T")
+
+		input = Input{
+			Region: &cloud.Region{
+				ObjectMeta: metav1.ObjectMeta{Name: "lab01"},
+				Spec: cloud.RegionSpec{
+					Baremetal: &cloud.RegionSpecBaremetal{
+						Endpoint: "https://10.0.0.2:6443",
+					},
+				},
+			},
+			Machine: &cloud.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "ant"},
+				Spec: cloud.MachineSpec{
+					Hardware: cloud.MachineSpecHardware{
+						HardwareProfileSpec: cloud.HardwareProfileSpec{
+							Talos: &cloud.HardwareTalos{
+								Installer:  "factory.talos.dev/installer/0123456789abcdef",
+								Overlay:    &cloud.TalosOverlay{Name: "nanopi-r5s", Image: "siderolabs/sbc-rockchip"},
+								KernelArgs: []string{"console=ttyS2,1500000n8"},
+							},
+						},
+					},
+					Interfaces: []cloud.Interface{
+						{
+							MAC: mustMAC("02:00:00:00:00:01"),
+							LinkConfig: cloud.LinkConfig{
+								Addresses: []string{"10.0.0.10/24"},
+								Gateway:   "10.0.0.1",
+							},
+						},
+					},
+					Disks: []cloud.Disk{
+						{Serial: "S123", Type: cloud.DiskTypeNVMe, Size: &size, Role: cloud.DiskRoleInstall},
+					},
+				},
+			},
+			Type: MachineTypeControlPlane,
+			Secrets: &SecretsBundle{
+				Cluster:    ClusterSecrets{ID: "cluster-id", Secret: "cluster-secret"},
+				Secrets:    Secrets{BootstrapToken: "abcdef.0123456789abcdef", SecretboxEncryptionSecret: "secretbox"},
+				TrustdInfo: TrustdInfo{Token: "trustd-token"},
+				Certs: CertificateSet{
+					Etcd:              CertificateAndKey{Crt: "ZXRjZC1jcnQ=", Key: "ZXRjZC1rZXk="},
+					K8s:               CertificateAndKey{Crt: "azhzLWNydA==", Key: "azhzLWtleQ=="},
+					K8sAggregator:     CertificateAndKey{Crt: "YWdncmVnYXRvci1jcnQ=", Key: "YWdncmVnYXRvci1rZXk="},
+					K8sServiceAccount: Key{Key: "c2VydmljZS1hY2NvdW50LWtleQ=="},
+					OS:                CertificateAndKey{Crt: "b3MtY3J0", Key: "b3Mta2V5"},
+				},
+			},
+			Version:     "v1.9.3",
+			InstallDisk: "/dev/sda",
+			Patch: map[string]any{
+				"machine": map[string]any{
+					"network": map[string]any{"nameservers": []any{"10.0.0.1"}},
+				},
+			},
+		}
+	})
+
+	It("should generate the configuration of a control plane", func() {
+		data, err := Generate(input)
+		Expect(err).NotTo(HaveOccurred())
+		expectGolden("controlplane.yaml", data)
+	})
+
+	It("should generate the configuration of a worker without private keys", func() {
+		input.Type = MachineTypeWorker
+
+		data, err := Generate(input)
+		Expect(err).NotTo(HaveOccurred())
+		expectGolden("worker.yaml", data)
+	})
+
+	It("should use the network of the region", func() {
+		input.Region.Spec.Baremetal.Network = &cloud.ClusterNetwork{
+			PodSubnets: []string{"10.32.0.0/16", "fd00:10:32::/56"},
+			DNSDomain:  "lab01.local",
+		}
+
+		data, err := Generate(input)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("dnsDomain: lab01.local"))
+		Expect(string(data)).To(ContainSubstring("podSubnets:\n    - 10.32.0.0/16\n    - fd00:10:32::/56\n"))
+		Expect(string(data)).To(ContainSubstring("serviceSubnets:\n    - " + DefaultServiceSubnet + "\n"))
+	})
+
+	It("should reject unknown fields", func() {
+		input.Patch = map[string]any{
+			"machine": map[string]any{"instal": map[string]any{"disk": "/dev/sdb"}},
+		}
+
+		_, err := Generate(input)
+		Expect(err).To(MatchError(ContainSubstring("unknown keys")))
+	})
+
+	It("should prefer the endpoint of the input", func() {
+		input.Region.Spec.Baremetal.Endpoint = ""
+		input.Endpoint = "https://ant:6443"
+
+		data, err := Generate(input)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("endpoint: https://ant:6443"))
+	})
+
+	It("should fail without endpoint", func() {
+		input.Region.Spec.Baremetal.Endpoint = ""
+
+		_, err := Generate(input)
+		Expect(err).To(MatchError(ContainSubstring("has no endpoint")))
+	})
+
+	It("should use the installer of the Image Factory for the overlay", func() {
+		input.Machine.Spec.Hardware.Talos.Installer = ""
+
+		data, err := Generate(input)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(
+			"image: factory.talos.dev/installer/e4544d822e89d1ffc55380bf9b5507147d168c890289dc619d35070c8651af21:v1.9.3"))
+	})
+
+	It("should use the default installer for hardware without overlay", func() {
+		input.Machine.Spec.Hardware.Talos = nil
+
+		data, err := Generate(input)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("image: " + DefaultInstaller + ":v1.9.3"))
+	})
+})
+
+var _ = Describe("Schematic", func() {
+	DescribeTable("should compute the ID of the Image Factory",
+		func(schematic Schematic, id string) {
+			Expect(schematic.ID()).To(Equal(id))
+		},
+		Entry("without customization", Schematic{},
+			"376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba"),
+		Entry("with overlay", Schematic{Overlay: SchematicOverlay{Image: "siderolabs/sbc-raspberrypi", Name: "rpi_generic"}},
+			"ee21ef4a5ef808a9b7484cc0dda0f25075021691c8c09a276591eedb638ea1f9"),
+	)
+})
+
+var _ = Describe("installDisk", func() {
+	size := resource.MustParse("500G")
+
+	DescribeTable("should select the install disk",
+		func(disks []cloud.Disk, disk string, selector *InstallDiskSelector) {
+			input := Input{
+				Machine:     &cloud.Machine{Spec: cloud.MachineSpec{Disks: disks}},
+				InstallDisk: "/dev/sda",
+			}
+
+			actualDisk, actualSelector := installDisk(input)
+			Expect(actualDisk).To(Equal(disk))
+			Expect(actualSelector).To(Equal(selector))
+		},
+		Entry("without disks", nil, "/dev/sda", nil),
+		Entry("without install disk",
+			[]cloud.Disk{{ByID: "/dev/disk/by-id/data", Role: cloud.DiskRoleData}},
+			"/dev/sda", nil),
+		Entry("by path",
+			[]cloud.Disk{{ByID: "/dev/disk/by-id/install", Serial: "S123", Role: cloud.DiskRoleInstall}},
+			"/dev/disk/by-id/install", nil),
+		Entry("by serial, type and size",
+			[]cloud.Disk{
+				{ByID: "/dev/disk/by-id/data", Role: cloud.DiskRoleData},
+				{Serial: "S123", Type: cloud.DiskTypeSSD, Size: &size, Role: cloud.DiskRoleInstall},
+			},
+			"", &InstallDiskSelector{Size: ">= 500G", Serial: "S123", Type: "ssd"}),
+	)
+})
+
+var _ = Describe("networkDevices", func() {
+	It("should use DHCP for interfaces without static addresses", func() {
+		spec := &cloud.MachineSpec{
+			Interfaces: []cloud.Interface{{MAC: mustMAC("02:00:00:00:00:01")}},
+		}
+
+		Expect(networkDevices(spec)).To(Equal([]Device{
+			{DeviceSelector: &DeviceSelector{HardwareAddr: "02:00:00:00:00:01"}, DHCP: true},
+		}))
+	})
+
+	It("should configure static addresses, routes and VLANs", func() {
+		spec := &cloud.MachineSpec{
+			Interfaces: []cloud.Interface{{
+				MAC: mustMAC("02:00:00:00:00:01"),
+				LinkConfig: cloud.LinkConfig{
+					Addresses: []string{"fd00::10/64"},
+					Gateway:   "fd00::1",
+					MTU:       9000,
+				},
+				VLANs: []cloud.VLAN{
+					{ID: 20, LinkConfig: cloud.LinkConfig{Addresses: []string{"10.0.20.10/24"}, Gateway: "10.0.20.1"}},
+					{ID: 30},
+				},
+			}},
+		}
+
+		Expect(networkDevices(spec)).To(Equal([]Device{{
+			DeviceSelector: &DeviceSelector{HardwareAddr: "02:00:00:00:00:01"},
+			Addresses:      []string{"fd00::10/64"},
+			Routes:         []Route{{Network: "::/0", Gateway: "fd00::1"}},
+			MTU:            9000,
+			VLANs: []VLAN{
+				{VLANID: 20, Addresses: []string{"10.0.20.10/24"}, Routes: []Route{{Network: "0.0.0.0/0", Gateway: "10.0.20.1"}}},
+				{VLANID: 30, DHCP: true},
+			},
+		}}))
+	})
+
+	It("should configure the members of bonds and bridges via them", func() {
+		spec := &cloud.MachineSpec{
+			Interfaces: []cloud.Interface{
+				{MAC: mustMAC("02:00:00:00:00:01"), Name: "eth0"},
+				{MAC: mustMAC("02:00:00:00:00:02"), Name: "eth1"},
+				{MAC: mustMAC("02:00:00:00:00:03"), Name: "eth2"},
+				{MAC: mustMAC("02:00:00:00:00:04")},
+			},
+			Bonds: []cloud.Bond{
+				{Name: "bond0", Interfaces: []string{"eth0", "eth1"}, Mode: cloud.BondMode8023AD},
+			},
+			Bridges: []cloud.Bridge{{
+				Name:       "br0",
+				Interfaces: []string{"bond0", "eth2"},
+				STP:        true,
+				LinkConfig: cloud.LinkConfig{Addresses: []string{"10.0.1.10/24"}},
+			}},
+		}
+
+		Expect(networkDevices(spec)).To(Equal([]Device{
+			{DeviceSelector: &DeviceSelector{HardwareAddr: "02:00:00:00:00:04"}, DHCP: true},
+			{
+				Interface: "bond0",
+				Bond: &Bond{
+					DeviceSelectors: []DeviceSelector{{HardwareAddr: "02:00:00:00:00:01"}, {HardwareAddr: "02:00:00:00:00:02"}},
+					Mode:            "802.3ad",
+				},
+			},
+			{
+				Interface: "br0",
+				Addresses: []string{"10.0.1.10/24"},
+				Bridge:    &Bridge{Interfaces: []string{"bond0", "eth2"}, STP: &STP{Enabled: true}},
+			},
+		}))
+	})
+})
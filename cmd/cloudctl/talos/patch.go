@@ -0,0 +1,58 @@
+package talos
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/nicklasfrahm/cloud/cmd/cloudctl/config"
+)
+
+// LoadPatch loads the patch of a machine from the patches directory of a
+// configuration repository. A machine without patch results in a nil patch.
+func LoadPatch(srcDir string, machine string) (map[string]any, error) {
+	file := path.Join(srcDir, config.TalosPatchesDir, machine+".yaml")
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read patch: %w", err)
+	}
+
+	patch := make(map[string]any)
+	if err := yaml.Unmarshal(data, &patch); err != nil {
+		return nil, fmt.Errorf("failed to decode patch %s: %w", file, err)
+	}
+
+	return patch, nil
+}
+
+// Merge merges the patch into the document. Maps are merged recursively,
+// while all other values, including lists, are replaced by the patch.
+// A null value in the patch removes the key from the document.
+func Merge(document map[string]any, patch map[string]any) map[string]any {
+	for key, value := range patch {
+		if value == nil {
+			delete(document, key)
+			continue
+		}
+
+		patchMap, patchIsMap := value.(map[string]any)
+		documentMap, documentIsMap := document[key].(map[string]any)
+		if patchIsMap && documentIsMap {
+			document[key] = Merge(documentMap, patchMap)
+			continue
+		}
+
+		document[key] = value
+	}
+
+	return document
+}
@@ -0,0 +1,105 @@
+package talos
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// SecretsBundle contains the secrets that are shared by all machines of a
+// cluster. The format is the same as the one of "talosctl gen secrets".
+type SecretsBundle struct {
+	Cluster    ClusterSecrets `json:"cluster"`
+	Secrets    Secrets        `json:"secrets"`
+	TrustdInfo TrustdInfo     `json:"trustdinfo"`
+	Certs      CertificateSet `json:"certs"`
+}
+
+// ClusterSecrets identify a cluster.
+type ClusterSecrets struct {
+	ID     string `json:"id"`
+	Secret string `json:"secret"`
+}
+
+// Secrets are the tokens and encryption keys of a cluster.
+type Secrets struct {
+	BootstrapToken            string `json:"bootstraptoken"`
+	SecretboxEncryptionSecret string `json:"secretboxencryptionsecret"`
+}
+
+// TrustdInfo contains the token that machines use to join a cluster.
+type TrustdInfo struct {
+	Token string `json:"token"`
+}
+
+// CertificateSet contains the certificate authorities of a cluster.
+type CertificateSet struct {
+	Etcd              CertificateAndKey `json:"etcd"`
+	K8s               CertificateAndKey `json:"k8s"`
+	K8sAggregator     CertificateAndKey `json:"k8saggregator"`
+	K8sServiceAccount Key               `json:"k8sserviceaccount"`
+	OS                CertificateAndKey `json:"os"`
+}
+
+// CertificateAndKey is a base64 encoded PEM certificate and private key.
+type CertificateAndKey struct {
+	Crt string `json:"crt"`
+	Key string `json:"key"`
+}
+
+// Key is a base64 encoded PEM private key.
+type Key struct {
+	Key string `json:"key"`
+}
+
+// LoadSecretsBundle loads a secrets bundle from a YAML file.
+func LoadSecretsBundle(file string) (*SecretsBundle, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets bundle: %w", err)
+	}
+
+	bundle := &SecretsBundle{}
+	if err := yaml.Unmarshal(data, bundle); err != nil {
+		return nil, fmt.Errorf("failed to decode secrets bundle: %w", err)
+	}
+
+	if err := bundle.Validate(); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+// Validate ensures that all secrets that are required to
+// generate the machine configuration are set.
+func (b *SecretsBundle) Validate() error {
+	required := []struct {
+		field string
+		value string
+	}{
+		{"cluster.id", b.Cluster.ID},
+		{"cluster.secret", b.Cluster.Secret},
+		{"secrets.bootstraptoken", b.Secrets.BootstrapToken},
+		{"secrets.secretboxencryptionsecret", b.Secrets.SecretboxEncryptionSecret},
+		{"trustdinfo.token", b.TrustdInfo.Token},
+		{"certs.etcd.crt", b.Certs.Etcd.Crt},
+		{"certs.etcd.key", b.Certs.Etcd.Key},
+		{"certs.k8s.crt", b.Certs.K8s.Crt},
+		{"certs.k8s.key", b.Certs.K8s.Key},
+		{"certs.k8saggregator.crt", b.Certs.K8sAggregator.Crt},
+		{"certs.k8saggregator.key", b.Certs.K8sAggregator.Key},
+		{"certs.k8sserviceaccount.key", b.Certs.K8sServiceAccount.Key},
+		{"certs.os.crt", b.Certs.OS.Crt},
+		{"certs.os.key", b.Certs.OS.Key},
+	}
+
+	for _, secret := range required {
+		if secret.value == "" {
+			return fmt.Errorf("invalid secrets bundle: %s is required", secret.field)
+		}
+	}
+
+	return nil
+}
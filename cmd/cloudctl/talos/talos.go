@@ -0,0 +1,19 @@
+package talos
+
+import "github.com/spf13/cobra"
+
+// RootCommand returns the talos command.
+func RootCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "talos",
+		Short: "Manage Talos Linux",
+		Long:  `Manage Talos Linux.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(GenConfigCommand())
+
+	return cmd
+}
@@ -0,0 +1,13 @@
+package talos
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTalos(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "talos suite")
+}
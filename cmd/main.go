@@ -20,6 +20,7 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -36,6 +37,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	cloudv1beta1 "github.com/nicklasfrahm/cloud/api/v1beta1"
+	"github.com/nicklasfrahm/cloud/internal/controller"
+	"github.com/nicklasfrahm/cloud/internal/dhcp"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -58,6 +61,8 @@ func main() {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
+	var dhcpConfig dhcp.Config
+	var powerInterval time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -68,6 +73,20 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&dhcpConfig.Interface, "dhcp-interface", "",
+		"The network interface that the DHCP server listens on. Leave empty to disable the DHCP server.")
+	flag.StringVar(&dhcpConfig.Range, "dhcp-range", "",
+		"The range of addresses that the DHCP server leases to machines, e.g. 10.0.0.100-10.0.0.199.")
+	flag.StringVar(&dhcpConfig.Router, "dhcp-router", "", "The default gateway that the DHCP server announces.")
+	flag.StringVar(&dhcpConfig.DNS, "dhcp-dns", "", "A comma-separated list of DNS servers that the DHCP server announces.")
+	flag.StringVar(&dhcpConfig.ScriptURL, "dhcp-ipxe-script-url", "",
+		"The URL of the iPXE script that netboot clients chainload. Leave empty to disable netbooting.")
+	flag.StringVar(&dhcpConfig.TFTPServer, "dhcp-tftp-server", "",
+		"The address of the TFTP server that serves iPXE to PXE clients, e.g. the one of labctl serve. "+
+			"Defaults to the address of the DHCP interface, but the manager does not serve TFTP itself.")
+	flag.DurationVar(&dhcpConfig.LeaseTime, "dhcp-lease-time", time.Hour, "The duration of DHCP leases.")
+	flag.DurationVar(&powerInterval, "power-interval", controller.DefaultPowerInterval,
+		"The interval at which the power state of machines is observed.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -143,8 +162,38 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = (&controller.MachineReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Machine")
+		os.Exit(1)
+	}
+	if err = (&controller.MachinePoolReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MachinePool")
+		os.Exit(1)
+	}
+	if err = (&controller.PowerReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		APIReader: mgr.GetAPIReader(),
+		Interval:  powerInterval,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Power")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
+	if dhcpConfig.Interface != "" {
+		if err := dhcpConfig.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to set up DHCP server")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -0,0 +1,10 @@
+// Package crd embeds the CustomResourceDefinitions generated by controller-gen,
+// so that manifests can be validated without access to a Kubernetes cluster.
+package crd
+
+import "embed"
+
+// Bases contains the generated CustomResourceDefinitions in the directory "bases".
+//
+//go:embed bases/*.yaml
+var Bases embed.FS
@@ -8,6 +8,42 @@ resource "talos_machine_secrets" "this" {
   talos_version = local.talos_version
 }
 
+# Export the secret bundle in the format of `talosctl gen secrets`,
+# which is used by `labctl talos gen-config` to generate the
+# machine configuration of the region.
+resource "local_sensitive_file" "secrets" {
+  filename = "${path.cwd}/deploy/tofu/out/${local.name}-secrets.yaml"
+  content = yamlencode({
+    cluster = talos_machine_secrets.this.machine_secrets.cluster
+    secrets = {
+      bootstraptoken = talos_machine_secrets.this.machine_secrets.secrets.bootstrap_token
+      secretboxencryptionsecret = talos_machine_secrets.this.machine_secrets.secrets.secretbox_encryption_secret
+    }
+    trustdinfo = talos_machine_secrets.this.machine_secrets.trustdinfo
+    certs = {
+      etcd = {
+        crt = talos_machine_secrets.this.machine_secrets.certs.etcd.cert
+        key = talos_machine_secrets.this.machine_secrets.certs.etcd.key
+      }
+      k8s = {
+        crt = talos_machine_secrets.this.machine_secrets.certs.k8s.cert
+        key = talos_machine_secrets.this.machine_secrets.certs.k8s.key
+      }
+      k8saggregator = {
+        crt = talos_machine_secrets.this.machine_secrets.certs.k8s_aggregator.cert
+        key = talos_machine_secrets.this.machine_secrets.certs.k8s_aggregator.key
+      }
+      k8sserviceaccount = {
+        key = talos_machine_secrets.this.machine_secrets.certs.k8s_serviceaccount.key
+      }
+      os = {
+        crt = talos_machine_secrets.this.machine_secrets.certs.os.cert
+        key = talos_machine_secrets.this.machine_secrets.certs.os.key
+      }
+    }
+  })
+}
+
 # Create a file with the Talos version.
 resource "local_file" "version" {
   filename = "${path.cwd}/deploy/tofu/out/${local.name}"
@@ -10,6 +10,10 @@ variable "region" {
         controlplanes = list(object({
           name = string
         }))
+        workers = optional(list(object({
+          name = string
+        })))
+        endpoint = optional(string)
       })
     })
   })
@@ -23,8 +27,11 @@ variable "machines" {
     })
     spec = object({
       hardware = object({
-        vendor = string
-        model = string
+        profileRef = optional(object({
+          name = string
+        }))
+        vendor = optional(string)
+        model = optional(string)
       })
       interfaces = list(object({
         mac = string
@@ -36,12 +43,25 @@ variable "machines" {
 variable "hardware_profiles" {
   description = "The configuration of all hardware profiles."
   type = map(object({
+    metadata = object({
+      name = string
+    })
     spec = object({
-      storage = object({
-        osDisk = object({
+      vendor = string
+      model = string
+      cpu = optional(object({
+        architecture = string
+        cores = optional(number)
+      }))
+      firmware = optional(string)
+      talos = optional(object({
+        installer = optional(string)
+        overlay = optional(object({
           name = string
-        })
-      })
+          image = string
+        }))
+        kernelArgs = optional(list(string))
+      }))
     })
   }))
 }
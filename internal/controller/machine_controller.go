@@ -0,0 +1,183 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	cloudv1beta1 "github.com/nicklasfrahm/cloud/api/v1beta1"
+)
+
+// DefaultStaleAfter is the default duration after which a machine
+// that has not been seen on the network is considered unreachable.
+const DefaultStaleAfter = 10 * time.Minute
+
+// MachineReconciler reconciles a Machine object
+type MachineReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// StaleAfter is the duration after which a machine that has not been
+	// seen on the network is considered unreachable. Defaults to DefaultStaleAfter.
+	StaleAfter time.Duration
+}
+
+// +kubebuilder:rbac:groups=cloud.nicklasfrahm.dev,resources=machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cloud.nicklasfrahm.dev,resources=machines/status,verbs=get;update;patch
+
+// Reconcile derives the conditions and the phase of a Machine from the
+// observations that were recorded in its status, e.g. by the DHCP server.
+func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	machine := &cloudv1beta1.Machine{}
+	if err := r.Get(ctx, req.NamespacedName, machine); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	patch := client.MergeFrom(machine.DeepCopy())
+
+	now := time.Now()
+	staleAfter := r.StaleAfter
+	if staleAfter == 0 {
+		staleAfter = DefaultStaleAfter
+	}
+
+	reachable := metav1.Condition{
+		Type:               cloudv1beta1.MachineConditionReachable,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: machine.Generation,
+		Reason:             "NeverSeen",
+		Message:            "The machine has never been seen on the network",
+	}
+	requeueAfter := time.Duration(0)
+	if lastSeen := machine.Status.LastSeen; lastSeen != nil {
+		age := now.Sub(lastSeen.Time)
+		if age < staleAfter {
+			reachable.Status = metav1.ConditionTrue
+			reachable.Reason = "Seen"
+			reachable.Message = "The machine has recently been seen on the network"
+
+			// Check again once the observation becomes stale.
+			requeueAfter = staleAfter - age
+		} else {
+			// The message must not depend on the current time, as every
+			// change of the status would trigger another reconciliation.
+			reachable.Reason = "Stale"
+			reachable.Message = fmt.Sprintf("The machine was last seen on the network at %s", lastSeen.UTC().Format(time.RFC3339))
+		}
+	}
+	meta.SetStatusCondition(&machine.Status.Conditions, reachable)
+
+	provisioned := metav1.Condition{
+		Type:               cloudv1beta1.MachineConditionProvisioned,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: machine.Generation,
+		Reason:             "OSNotInstalled",
+		Message:            "No operating system has been reported",
+	}
+	if machine.Status.OSVersion != "" {
+		provisioned.Status = metav1.ConditionTrue
+		provisioned.Reason = "OSInstalled"
+		provisioned.Message = fmt.Sprintf("Operating system %s is installed", machine.Status.OSVersion)
+	}
+	meta.SetStatusCondition(&machine.Status.Conditions, provisioned)
+
+	ready := metav1.Condition{
+		Type:               cloudv1beta1.MachineConditionReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: machine.Generation,
+	}
+	switch {
+	case machine.Spec.Decommissioned:
+		ready.Reason = "Decommissioned"
+		ready.Message = "The machine has been decommissioned"
+	case provisioned.Status != metav1.ConditionTrue:
+		ready.Reason = provisioned.Reason
+		ready.Message = provisioned.Message
+	case reachable.Status != metav1.ConditionTrue:
+		ready.Reason = reachable.Reason
+		ready.Message = reachable.Message
+	default:
+		ready.Status = metav1.ConditionTrue
+		ready.Reason = "Ready"
+		ready.Message = "The machine is ready"
+	}
+	meta.SetStatusCondition(&machine.Status.Conditions, ready)
+
+	switch {
+	case machine.Spec.Decommissioned:
+		machine.Status.Phase = cloudv1beta1.MachinePhaseDecommissioned
+	case ready.Status == metav1.ConditionTrue:
+		machine.Status.Phase = cloudv1beta1.MachinePhaseReady
+	case machine.Status.LastSeen != nil:
+		machine.Status.Phase = cloudv1beta1.MachinePhaseProvisioning
+	default:
+		machine.Status.Phase = cloudv1beta1.MachinePhaseRegistered
+	}
+
+	machine.Status.ObservedGeneration = machine.Generation
+
+	if err := r.Status().Patch(ctx, machine, patch); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update status: %w", err)
+	}
+
+	logger.V(1).Info("Reconciled machine", "phase", machine.Status.Phase)
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// machineObservationsChanged filters for updates that change the spec of a
+// Machine or the observations that its conditions are derived from. Updates
+// of the conditions themselves or of the power state are ignored.
+var machineObservationsChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldMachine, ok := e.ObjectOld.(*cloudv1beta1.Machine)
+		if !ok {
+			return false
+		}
+
+		newMachine, ok := e.ObjectNew.(*cloudv1beta1.Machine)
+		if !ok {
+			return false
+		}
+
+		return oldMachine.Generation != newMachine.Generation ||
+			!oldMachine.Status.LastSeen.Equal(newMachine.Status.LastSeen) ||
+			oldMachine.Status.OSVersion != newMachine.Status.OSVersion
+	},
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cloudv1beta1.Machine{}, builder.WithPredicates(machineObservationsChanged)).
+		Named("machine").
+		Complete(r)
+}
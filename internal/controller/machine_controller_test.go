@@ -0,0 +1,147 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	cloudv1beta1 "github.com/nicklasfrahm/cloud/api/v1beta1"
+)
+
+var _ = Describe("MachineReconciler", func() {
+	var (
+		ctx        context.Context
+		k8sClient  client.Client
+		reconciler *MachineReconciler
+	)
+
+	key := client.ObjectKey{Name: "ant", Namespace: "default"}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		Expect(cloudv1beta1.AddToScheme(scheme)).To(Succeed())
+
+		k8sClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&cloudv1beta1.Machine{}).
+			WithObjects(&cloudv1beta1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			}).
+			Build()
+
+		reconciler = &MachineReconciler{Client: k8sClient, Scheme: scheme}
+	})
+
+	// observe records the observations in the status of the machine.
+	observe := func(lastSeen time.Duration, osVersion string) {
+		machine := &cloudv1beta1.Machine{}
+		Expect(k8sClient.Get(ctx, key, machine)).To(Succeed())
+
+		seen := metav1.NewTime(time.Now().Add(-lastSeen))
+		machine.Status.LastSeen = &seen
+		machine.Status.OSVersion = osVersion
+		Expect(k8sClient.Status().Update(ctx, machine)).To(Succeed())
+	}
+
+	reconcile := func() (*cloudv1beta1.Machine, ctrl.Result) {
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		machine := &cloudv1beta1.Machine{}
+		Expect(k8sClient.Get(ctx, key, machine)).To(Succeed())
+
+		return machine, result
+	}
+
+	condition := func(machine *cloudv1beta1.Machine, conditionType string) *metav1.Condition {
+		condition := meta.FindStatusCondition(machine.Status.Conditions, conditionType)
+		Expect(condition).NotTo(BeNil())
+		return condition
+	}
+
+	It("should register machines that were never seen", func() {
+		machine, result := reconcile()
+		Expect(machine.Status.Phase).To(Equal(cloudv1beta1.MachinePhaseRegistered))
+		Expect(condition(machine, cloudv1beta1.MachineConditionReachable).Reason).To(Equal("NeverSeen"))
+		Expect(condition(machine, cloudv1beta1.MachineConditionReady).Reason).To(Equal("OSNotInstalled"))
+		Expect(result.RequeueAfter).To(BeZero())
+	})
+
+	It("should mark recently seen machines with an operating system as ready", func() {
+		observe(time.Minute, "Talos v1.9.3")
+
+		machine, result := reconcile()
+		Expect(machine.Status.Phase).To(Equal(cloudv1beta1.MachinePhaseReady))
+		Expect(condition(machine, cloudv1beta1.MachineConditionReachable).Reason).To(Equal("Seen"))
+		Expect(condition(machine, cloudv1beta1.MachineConditionReady).Status).To(Equal(metav1.ConditionTrue))
+		Expect(result.RequeueAfter).To(BeNumerically("~", DefaultStaleAfter-time.Minute, time.Second))
+	})
+
+	It("should mark machines that were not seen recently as unreachable", func() {
+		observe(time.Hour, "Talos v1.9.3")
+
+		machine, result := reconcile()
+		Expect(machine.Status.Phase).To(Equal(cloudv1beta1.MachinePhaseProvisioning))
+		Expect(condition(machine, cloudv1beta1.MachineConditionReachable).Reason).To(Equal("Stale"))
+		Expect(condition(machine, cloudv1beta1.MachineConditionReady).Reason).To(Equal("Stale"))
+		Expect(condition(machine, cloudv1beta1.MachineConditionReachable).Message).To(
+			ContainSubstring(machine.Status.LastSeen.UTC().Format(time.RFC3339)))
+		Expect(result.RequeueAfter).To(BeZero())
+
+		// The status must be stable, so that it does not trigger another reconciliation.
+		again, _ := reconcile()
+		Expect(again.Status).To(Equal(machine.Status))
+	})
+
+	It("should mark decommissioned machines", func() {
+		machine := &cloudv1beta1.Machine{}
+		Expect(k8sClient.Get(ctx, key, machine)).To(Succeed())
+		machine.Spec.Decommissioned = true
+		Expect(k8sClient.Update(ctx, machine)).To(Succeed())
+		observe(time.Minute, "Talos v1.9.3")
+
+		machine, _ = reconcile()
+		Expect(machine.Status.Phase).To(Equal(cloudv1beta1.MachinePhaseDecommissioned))
+		Expect(condition(machine, cloudv1beta1.MachineConditionReady).Reason).To(Equal("Decommissioned"))
+	})
+
+	It("should ignore updates of the conditions", func() {
+		machine, _ := reconcile()
+
+		updated := machine.DeepCopy()
+		updated.Status.Phase = cloudv1beta1.MachinePhaseReady
+		updated.Status.Power = &cloudv1beta1.MachinePowerStatus{State: cloudv1beta1.PowerStateOn}
+		Expect(machineObservationsChanged.Update(event.UpdateEvent{ObjectOld: machine, ObjectNew: updated})).To(BeFalse())
+
+		seen := metav1.Now()
+		updated.Status.LastSeen = &seen
+		Expect(machineObservationsChanged.Update(event.UpdateEvent{ObjectOld: machine, ObjectNew: updated})).To(BeTrue())
+	})
+})
@@ -0,0 +1,181 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cloudv1beta1 "github.com/nicklasfrahm/cloud/api/v1beta1"
+)
+
+// MachinePoolReconciler reconciles a MachinePool object
+type MachinePoolReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=cloud.nicklasfrahm.dev,resources=machinepools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cloud.nicklasfrahm.dev,resources=machinepools/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cloud.nicklasfrahm.dev,resources=machines,verbs=get;list;watch
+
+// Reconcile resolves the selector of a MachinePool against the Machines
+// in its namespace and reports the matched Machines in its status.
+func (r *MachinePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	pool := &cloudv1beta1.MachinePool{}
+	if err := r.Get(ctx, req.NamespacedName, pool); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	patch := client.MergeFrom(pool.DeepCopy())
+
+	pool.Status.ObservedGeneration = pool.Generation
+
+	selector, err := pool.Spec.Selector.AsSelector()
+	if err != nil {
+		// An invalid selector can only be fixed by changing the spec,
+		// so there is no point in retrying.
+		meta.SetStatusCondition(&pool.Status.Conditions, metav1.Condition{
+			Type:               cloudv1beta1.MachinePoolConditionReady,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: pool.Generation,
+			Reason:             "InvalidSelector",
+			Message:            err.Error(),
+		})
+
+		if err := r.Status().Patch(ctx, pool, patch); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update status: %w", err)
+		}
+
+		return ctrl.Result{}, nil
+	}
+
+	machines := &cloudv1beta1.MachineList{}
+	if err := r.List(ctx, machines, client.InNamespace(pool.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list machines: %w", err)
+	}
+
+	names := make([]string, 0, len(machines.Items))
+	readyCount := int32(0)
+	for _, machine := range machines.Items {
+		names = append(names, machine.Name)
+
+		if meta.IsStatusConditionTrue(machine.Status.Conditions, cloudv1beta1.MachineConditionReady) {
+			readyCount++
+		}
+	}
+	sort.Strings(names)
+
+	pool.Status.MachineCount = int32(len(names))
+	pool.Status.ReadyMachineCount = readyCount
+	pool.Status.Machines = names
+
+	condition := metav1.Condition{
+		Type:               cloudv1beta1.MachinePoolConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: pool.Generation,
+		Reason:             "MachinesReady",
+		Message:            fmt.Sprintf("%d of %d machines are ready", readyCount, len(names)),
+	}
+	switch {
+	case len(names) == 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "NoMachines"
+		condition.Message = "No machines match the selector"
+	case int(readyCount) < len(names):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "MachinesNotReady"
+	}
+	meta.SetStatusCondition(&pool.Status.Conditions, condition)
+
+	if err := r.Status().Patch(ctx, pool, patch); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update status: %w", err)
+	}
+
+	logger.V(1).Info("Reconciled machine pool", "machines", len(names), "ready", readyCount)
+
+	return ctrl.Result{}, nil
+}
+
+// machinePoolsForMachine enqueues all MachinePools in the namespace of a Machine.
+// The pools are not filtered by their selector, because a Machine whose labels
+// changed also needs to be removed from the pools that matched it previously.
+func (r *MachinePoolReconciler) machinePoolsForMachine(ctx context.Context, machine client.Object) []reconcile.Request {
+	pools := &cloudv1beta1.MachinePoolList{}
+	if err := r.List(ctx, pools, client.InNamespace(machine.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list machine pools")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(pools.Items))
+	for _, pool := range pools.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: pool.Namespace, Name: pool.Name},
+		})
+	}
+
+	return requests
+}
+
+// machineReadinessChanged filters for updates that change whether a Machine is ready.
+var machineReadinessChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldMachine, ok := e.ObjectOld.(*cloudv1beta1.Machine)
+		if !ok {
+			return false
+		}
+
+		newMachine, ok := e.ObjectNew.(*cloudv1beta1.Machine)
+		if !ok {
+			return false
+		}
+
+		wasReady := meta.IsStatusConditionTrue(oldMachine.Status.Conditions, cloudv1beta1.MachineConditionReady)
+		isReady := meta.IsStatusConditionTrue(newMachine.Status.Conditions, cloudv1beta1.MachineConditionReady)
+
+		return wasReady != isReady
+	},
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MachinePoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cloudv1beta1.MachinePool{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&cloudv1beta1.Machine{},
+			handler.EnqueueRequestsFromMapFunc(r.machinePoolsForMachine),
+			builder.WithPredicates(predicate.Or(predicate.LabelChangedPredicate{}, machineReadinessChanged)),
+		).
+		Named("machinepool").
+		Complete(r)
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cloudv1beta1 "github.com/nicklasfrahm/cloud/api/v1beta1"
+)
+
+var _ = Describe("MachinePoolReconciler", func() {
+	var (
+		ctx        context.Context
+		k8sClient  client.Client
+		reconciler *MachinePoolReconciler
+	)
+
+	key := client.ObjectKey{Name: "lab01", Namespace: "default"}
+
+	// machine returns a Machine with the labels and readiness.
+	machine := func(name string, namespace string, labels map[string]string, ready bool) *cloudv1beta1.Machine {
+		status := metav1.ConditionFalse
+		if ready {
+			status = metav1.ConditionTrue
+		}
+
+		return &cloudv1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			Status: cloudv1beta1.MachineStatus{
+				Conditions: []metav1.Condition{{
+					Type:               cloudv1beta1.MachineConditionReady,
+					Status:             status,
+					Reason:             "Test",
+					LastTransitionTime: metav1.Now(),
+				}},
+			},
+		}
+	}
+
+	// setup creates a client with the pool and the Machines.
+	setup := func(selector cloudv1beta1.Selector, objects ...client.Object) {
+		scheme := runtime.NewScheme()
+		Expect(cloudv1beta1.AddToScheme(scheme)).To(Succeed())
+
+		pool := &cloudv1beta1.MachinePool{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, Generation: 2},
+			Spec:       cloudv1beta1.MachinePoolSpec{Selector: selector},
+		}
+
+		k8sClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&cloudv1beta1.MachinePool{}, &cloudv1beta1.Machine{}).
+			WithObjects(append(objects, pool)...).
+			Build()
+
+		reconciler = &MachinePoolReconciler{Client: k8sClient, Scheme: scheme}
+	}
+
+	reconcile := func() *cloudv1beta1.MachinePool {
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+
+		pool := &cloudv1beta1.MachinePool{}
+		Expect(k8sClient.Get(ctx, key, pool)).To(Succeed())
+
+		return pool
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should count the matched and ready machines", func() {
+		pool := map[string]string{"pool": "lab01"}
+		setup(
+			cloudv1beta1.Selector{MatchLabels: pool},
+			machine("ant", "default", pool, true),
+			machine("bee", "default", pool, false),
+			machine("cow", "default", map[string]string{"pool": "lab02"}, true),
+			machine("dog", "other", pool, true),
+		)
+
+		status := reconcile().Status
+		Expect(status.ObservedGeneration).To(Equal(int64(2)))
+		Expect(status.Machines).To(Equal([]string{"ant", "bee"}))
+		Expect(status.MachineCount).To(Equal(int32(2)))
+		Expect(status.ReadyMachineCount).To(Equal(int32(1)))
+
+		condition := meta.FindStatusCondition(status.Conditions, cloudv1beta1.MachinePoolConditionReady)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("MachinesNotReady"))
+		Expect(condition.Message).To(Equal("1 of 2 machines are ready"))
+	})
+
+	It("should be ready if all machines are ready", func() {
+		setup(
+			cloudv1beta1.Selector{MatchExpressions: []cloudv1beta1.SelectorRequirement{
+				{Key: "zone", Operator: cloudv1beta1.SelectorOpIn, Values: []string{"home", "office"}},
+			}},
+			machine("ant", "default", map[string]string{"zone": "home"}, true),
+			machine("bee", "default", map[string]string{"zone": "office"}, true),
+		)
+
+		status := reconcile().Status
+		Expect(status.MachineCount).To(Equal(int32(2)))
+		Expect(status.ReadyMachineCount).To(Equal(int32(2)))
+
+		condition := meta.FindStatusCondition(status.Conditions, cloudv1beta1.MachinePoolConditionReady)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal("MachinesReady"))
+	})
+
+	It("should report a pool without machines", func() {
+		setup(
+			cloudv1beta1.Selector{MatchLabels: map[string]string{"pool": "lab01"}},
+			machine("ant", "default", map[string]string{"pool": "lab02"}, true),
+		)
+
+		status := reconcile().Status
+		Expect(status.Machines).To(BeEmpty())
+		Expect(status.MachineCount).To(BeZero())
+		Expect(status.ReadyMachineCount).To(BeZero())
+
+		condition := meta.FindStatusCondition(status.Conditions, cloudv1beta1.MachinePoolConditionReady)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("NoMachines"))
+	})
+
+	It("should reject an invalid selector without retrying", func() {
+		setup(
+			cloudv1beta1.Selector{MatchExpressions: []cloudv1beta1.SelectorRequirement{
+				{Key: "zone", Operator: cloudv1beta1.SelectorOpIn},
+			}},
+			machine("ant", "default", map[string]string{"zone": "home"}, true),
+		)
+
+		status := reconcile().Status
+		Expect(status.ObservedGeneration).To(Equal(int64(2)))
+		Expect(status.Machines).To(BeEmpty())
+
+		condition := meta.FindStatusCondition(status.Conditions, cloudv1beta1.MachinePoolConditionReady)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("InvalidSelector"))
+	})
+
+	It("should enqueue all pools in the namespace of a machine", func() {
+		setup(
+			cloudv1beta1.Selector{MatchLabels: map[string]string{"pool": "lab01"}},
+			&cloudv1beta1.MachinePool{ObjectMeta: metav1.ObjectMeta{Name: "lab02", Namespace: "default"}},
+			&cloudv1beta1.MachinePool{ObjectMeta: metav1.ObjectMeta{Name: "lab03", Namespace: "other"}},
+		)
+
+		// The labels of the machine are irrelevant, as it
+		// may have been removed from a pool it matched before.
+		requests := reconciler.machinePoolsForMachine(ctx, machine("ant", "default", nil, true))
+		Expect(requests).To(ConsistOf(
+			ctrl.Request{NamespacedName: types.NamespacedName{Name: "lab01", Namespace: "default"}},
+			ctrl.Request{NamespacedName: types.NamespacedName{Name: "lab02", Namespace: "default"}},
+		))
+	})
+})
@@ -0,0 +1,218 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	cloudv1beta1 "github.com/nicklasfrahm/cloud/api/v1beta1"
+	"github.com/nicklasfrahm/cloud/pkg/power"
+)
+
+// DefaultPowerInterval is the default interval at which
+// the power state of a machine is observed.
+const DefaultPowerInterval = time.Minute
+
+// PowerReconciler reconciles the power state of a Machine object.
+type PowerReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// APIReader reads the credentials directly from the API server, so
+	// that the manager does not cache all Secrets of the cluster.
+	APIReader client.Reader
+
+	// Interval is the interval at which the power state of a machine
+	// is observed. Defaults to DefaultPowerInterval.
+	Interval time.Duration
+}
+
+// +kubebuilder:rbac:groups=cloud.nicklasfrahm.dev,resources=machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cloud.nicklasfrahm.dev,resources=machines/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+// Reconcile observes the power state of a Machine via the configured driver
+// and powers the machine on or off if it differs from the desired state.
+// Drivers that cannot observe the power state, e.g. Wake-on-LAN, derive it
+// from the Reachable condition of the machine.
+func (r *PowerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	machine := &cloudv1beta1.Machine{}
+	if err := r.Get(ctx, req.NamespacedName, machine); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if machine.Spec.Power == nil {
+		return ctrl.Result{}, nil
+	}
+
+	interval := r.Interval
+	if interval == 0 {
+		interval = DefaultPowerInterval
+	}
+
+	patch := client.MergeFrom(machine.DeepCopy())
+
+	now := metav1.Now()
+	status := &cloudv1beta1.MachinePowerStatus{
+		State:        cloudv1beta1.PowerStateUnknown,
+		LastObserved: &now,
+	}
+	if machine.Status.Power != nil {
+		status.LastTransition = machine.Status.Power.LastTransition
+	}
+
+	managed := metav1.Condition{
+		Type:               cloudv1beta1.MachineConditionPowerManaged,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Managed",
+		Message:            fmt.Sprintf("The power is managed via the %s driver", machine.Spec.Power.Driver),
+		ObservedGeneration: machine.Generation,
+	}
+
+	// The image of the manager does not ship ipmitool, so the
+	// IPMI driver can only be used via labctl.
+	unsupported := machine.Spec.Power.Driver == cloudv1beta1.PowerDriverIPMI
+	if unsupported {
+		managed.Status = metav1.ConditionFalse
+		managed.Reason = "UnsupportedDriver"
+		managed.Message = "The ipmi driver requires ipmitool, which is not available in the manager, use labctl instead"
+	} else if err := r.reconcilePower(ctx, machine, status); err != nil {
+		logger.Error(err, "Failed to reconcile power state")
+		status.Error = err.Error()
+	}
+	machine.Status.Power = status
+	meta.SetStatusCondition(&machine.Status.Conditions, managed)
+
+	if err := r.Status().Patch(ctx, machine, patch); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update status: %w", err)
+	}
+
+	logger.V(1).Info("Reconciled power state", "state", status.State)
+
+	// The driver is only evaluated again if the spec changes.
+	if unsupported {
+		return ctrl.Result{}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// reconcilePower observes the power state and changes it to the desired
+// state. The status is updated with the observed state and the time of
+// the last transition.
+func (r *PowerReconciler) reconcilePower(ctx context.Context, machine *cloudv1beta1.Machine, status *cloudv1beta1.MachinePowerStatus) error {
+	driver, err := r.driver(ctx, machine)
+	if err != nil {
+		return err
+	}
+
+	state, err := driver.State(ctx)
+	switch {
+	case errors.Is(err, power.ErrUnsupported):
+		state = power.StateOff
+		if meta.IsStatusConditionTrue(machine.Status.Conditions, cloudv1beta1.MachineConditionReachable) {
+			state = power.StateOn
+		}
+	case err != nil:
+		return fmt.Errorf("failed to observe power state: %w", err)
+	}
+	status.State = cloudv1beta1.PowerState(state)
+
+	desired := machine.Spec.Power.State
+	if desired == "" || status.State == cloudv1beta1.PowerStateUnknown || status.State == desired {
+		return nil
+	}
+
+	switch desired {
+	case cloudv1beta1.PowerStateOn:
+		err = driver.On(ctx)
+	case cloudv1beta1.PowerStateOff:
+		err = driver.Off(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to power %s machine: %w", strings.ToLower(string(desired)), err)
+	}
+
+	log.FromContext(ctx).Info("Changed power state", "from", status.State, "to", desired)
+	status.LastTransition = status.LastObserved
+
+	return nil
+}
+
+// driver returns the driver of the machine with the
+// credentials of the referenced Secret, if any.
+func (r *PowerReconciler) driver(ctx context.Context, machine *cloudv1beta1.Machine) (power.Driver, error) {
+	spec := machine.Spec.Power
+
+	options := power.Options{
+		Driver:             string(spec.Driver),
+		Endpoint:           spec.Endpoint,
+		Outlet:             int(spec.Outlet),
+		InsecureSkipVerify: spec.InsecureSkipVerify,
+	}
+
+	for _, iface := range machine.Spec.Interfaces {
+		options.MACs = append(options.MACs, net.HardwareAddr(iface.MAC))
+	}
+
+	if ref := spec.CredentialsSecretRef; ref != nil {
+		secret := &corev1.Secret{}
+		if err := r.APIReader.Get(ctx, types.NamespacedName{Namespace: machine.Namespace, Name: ref.Name}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get credentials: %w", err)
+		}
+
+		options.Credentials = power.Credentials{
+			Username: string(secret.Data["username"]),
+			Password: string(secret.Data["password"]),
+		}
+	}
+
+	driver, err := power.NewDriver(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create power driver: %w", err)
+	}
+
+	return driver, nil
+}
+
+// SetupWithManager sets up the controller with the Manager. Changes of the
+// status do not trigger a reconciliation, as the power state is observed
+// periodically anyway.
+func (r *PowerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cloudv1beta1.Machine{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Named("power").
+		Complete(r)
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cloudv1beta1 "github.com/nicklasfrahm/cloud/api/v1beta1"
+)
+
+var _ = Describe("PowerReconciler", func() {
+	const system = "/redfish/v1/Systems/1"
+
+	var (
+		ctx        context.Context
+		k8sClient  client.Client
+		reconciler *PowerReconciler
+		server     *httptest.Server
+		mutex      sync.Mutex
+		powerState string
+		resets     []string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		powerState = "Off"
+		resets = nil
+
+		// The BMC implements the parts of the Redfish API that the driver uses.
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if username, password, _ := r.BasicAuth(); username != "admin" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			switch r.URL.Path {
+			case system:
+				json.NewEncoder(w).Encode(map[string]string{"PowerState": powerState})
+			case system + "/Actions/ComputerSystem.Reset":
+				body := struct{ ResetType string }{}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				resets = append(resets, body.ResetType)
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		scheme := runtime.NewScheme()
+		Expect(cloudv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+		k8sClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&cloudv1beta1.Machine{}).
+			WithObjects(
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "ant-bmc", Namespace: "default"},
+					Data: map[string][]byte{
+						"username": []byte("admin"),
+						"password": []byte("secret"),
+					},
+				},
+				&cloudv1beta1.Machine{
+					ObjectMeta: metav1.ObjectMeta{Name: "ant", Namespace: "default"},
+					Spec: cloudv1beta1.MachineSpec{
+						Power: &cloudv1beta1.MachineSpecPower{
+							Driver:               cloudv1beta1.PowerDriverRedfish,
+							Endpoint:             server.URL + system,
+							CredentialsSecretRef: &cloudv1beta1.SecretReference{Name: "ant-bmc"},
+						},
+					},
+				},
+			).
+			Build()
+
+		reconciler = &PowerReconciler{Client: k8sClient, Scheme: scheme, APIReader: k8sClient}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	reconcile := func() *cloudv1beta1.Machine {
+		key := client.ObjectKey{Name: "ant", Namespace: "default"}
+
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(DefaultPowerInterval))
+
+		machine := &cloudv1beta1.Machine{}
+		Expect(k8sClient.Get(ctx, key, machine)).To(Succeed())
+		Expect(machine.Status.Power).NotTo(BeNil())
+
+		return machine
+	}
+
+	setDesiredState := func(state cloudv1beta1.PowerState) {
+		machine := &cloudv1beta1.Machine{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "ant", Namespace: "default"}, machine)).To(Succeed())
+		machine.Spec.Power.State = state
+		Expect(k8sClient.Update(ctx, machine)).To(Succeed())
+	}
+
+	It("should only observe the power state if no state is desired", func() {
+		machine := reconcile()
+		Expect(machine.Status.Power.State).To(Equal(cloudv1beta1.PowerStateOff))
+		Expect(meta.IsStatusConditionTrue(machine.Status.Conditions, cloudv1beta1.MachineConditionPowerManaged)).To(BeTrue())
+		Expect(machine.Status.Power.LastObserved).NotTo(BeNil())
+		Expect(machine.Status.Power.LastTransition).To(BeNil())
+		Expect(resets).To(BeEmpty())
+	})
+
+	It("should power on the machine if it is desired", func() {
+		setDesiredState(cloudv1beta1.PowerStateOn)
+
+		machine := reconcile()
+		Expect(machine.Status.Power.Error).To(BeEmpty())
+		Expect(machine.Status.Power.LastTransition).NotTo(BeNil())
+		Expect(resets).To(Equal([]string{"On"}))
+
+		mutex.Lock()
+		powerState = "On"
+		mutex.Unlock()
+
+		machine = reconcile()
+		Expect(machine.Status.Power.State).To(Equal(cloudv1beta1.PowerStateOn))
+		Expect(resets).To(Equal([]string{"On"}))
+	})
+
+	It("should power off the machine if it is desired", func() {
+		powerState = "On"
+		setDesiredState(cloudv1beta1.PowerStateOff)
+
+		reconcile()
+		Expect(resets).To(Equal([]string{"ForceOff"}))
+	})
+
+	It("should record errors in the status", func() {
+		server.Close()
+		setDesiredState(cloudv1beta1.PowerStateOn)
+
+		machine := reconcile()
+		Expect(machine.Status.Power.State).To(Equal(cloudv1beta1.PowerStateUnknown))
+		Expect(machine.Status.Power.Error).To(ContainSubstring("failed to observe power state"))
+	})
+
+	It("should reject the IPMI driver", func() {
+		key := client.ObjectKey{Name: "ant", Namespace: "default"}
+
+		machine := &cloudv1beta1.Machine{}
+		Expect(k8sClient.Get(ctx, key, machine)).To(Succeed())
+		machine.Spec.Power.Driver = cloudv1beta1.PowerDriverIPMI
+		Expect(k8sClient.Update(ctx, machine)).To(Succeed())
+
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+
+		Expect(k8sClient.Get(ctx, key, machine)).To(Succeed())
+		Expect(machine.Status.Power.State).To(Equal(cloudv1beta1.PowerStateUnknown))
+		condition := meta.FindStatusCondition(machine.Status.Conditions, cloudv1beta1.MachineConditionPowerManaged)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("UnsupportedDriver"))
+	})
+})
@@ -0,0 +1,255 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dhcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cloudv1beta1 "github.com/nicklasfrahm/cloud/api/v1beta1"
+	"github.com/nicklasfrahm/cloud/pkg/dhcp"
+)
+
+// Range is an inclusive range of IPv4 addresses.
+type Range struct {
+	Start netip.Addr
+	End   netip.Addr
+}
+
+// ParseRange parses a range of IPv4 addresses, e.g. "10.0.0.100-10.0.0.199".
+func ParseRange(value string) (Range, error) {
+	start, end, found := strings.Cut(value, "-")
+	if !found {
+		return Range{}, fmt.Errorf("invalid address range: %s", value)
+	}
+
+	r := Range{}
+	var err error
+	if r.Start, err = netip.ParseAddr(strings.TrimSpace(start)); err != nil {
+		return Range{}, fmt.Errorf("invalid start of address range: %w", err)
+	}
+
+	if r.End, err = netip.ParseAddr(strings.TrimSpace(end)); err != nil {
+		return Range{}, fmt.Errorf("invalid end of address range: %w", err)
+	}
+
+	if !r.Start.Is4() || !r.End.Is4() {
+		return Range{}, fmt.Errorf("address range must only contain IPv4 addresses: %s", value)
+	}
+
+	if r.End.Less(r.Start) {
+		return Range{}, fmt.Errorf("start of address range must not be after its end: %s", value)
+	}
+
+	return r, nil
+}
+
+// Contains returns true if the address is part of the range.
+func (r Range) Contains(addr netip.Addr) bool {
+	return !addr.Less(r.Start) && !r.End.Less(addr)
+}
+
+// Inventory provides the reservations of the DHCP server from the Machines
+// in the cluster and records the leases in the status of the Machines.
+// Interfaces with a static IPv4 address receive that address, while every
+// other interface of a Machine receives a stable address from the range.
+// Addresses that were recorded in the status of a Machine are reused,
+// so that machines keep their address across restarts of the server.
+type Inventory struct {
+	client.Client
+
+	// Range is the range of addresses that are leased to machines.
+	Range Range
+
+	mutex sync.Mutex
+	// offers are the addresses that were offered,
+	// but not yet recorded in the status of a Machine.
+	offers map[string]netip.Addr
+}
+
+// +kubebuilder:rbac:groups=cloud.nicklasfrahm.dev,resources=machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cloud.nicklasfrahm.dev,resources=machines/status,verbs=get;update;patch
+
+// Reserve returns the reservation of the interface with the given MAC address.
+// Unknown and decommissioned machines have no reservation.
+func (i *Inventory) Reserve(ctx context.Context, mac net.HardwareAddr) (*dhcp.Reservation, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	machines := &cloudv1beta1.MachineList{}
+	if err := i.List(ctx, machines); err != nil {
+		return nil, fmt.Errorf("failed to list machines: %w", err)
+	}
+
+	machine := findMachine(machines, mac)
+	if machine == nil || machine.Spec.Decommissioned {
+		return nil, nil
+	}
+
+	key := mac.String()
+	addr, ok := staticAddress(machine, mac)
+	if !ok {
+		addr, ok = i.recordedAddress(machine, mac)
+	}
+	if !ok {
+		addr, ok = i.offers[key]
+	}
+	if !ok {
+		var err error
+		if addr, err = i.allocate(machines); err != nil {
+			return nil, err
+		}
+	}
+
+	if i.offers == nil {
+		i.offers = make(map[string]netip.Addr)
+	}
+	i.offers[key] = addr
+
+	return &dhcp.Reservation{
+		IP:       net.IP(addr.AsSlice()),
+		Hostname: machine.Name,
+	}, nil
+}
+
+// Record records the lease in the status of the machine.
+func (i *Inventory) Record(ctx context.Context, lease dhcp.Lease) error {
+	machines := &cloudv1beta1.MachineList{}
+	if err := i.List(ctx, machines); err != nil {
+		return fmt.Errorf("failed to list machines: %w", err)
+	}
+
+	machine := findMachine(machines, lease.MAC)
+	if machine == nil {
+		return fmt.Errorf("machine with MAC address %s not found", lease.MAC)
+	}
+
+	patch := client.MergeFrom(machine.DeepCopy())
+
+	now := metav1.Now()
+	machine.Status.LastSeen = &now
+
+	index := slices.IndexFunc(machine.Status.Interfaces, func(status cloudv1beta1.InterfaceStatus) bool {
+		return bytes.Equal(status.MAC, lease.MAC)
+	})
+	if index < 0 {
+		machine.Status.Interfaces = append(machine.Status.Interfaces, cloudv1beta1.InterfaceStatus{
+			MAC: cloudv1beta1.MAC(lease.MAC),
+		})
+		index = len(machine.Status.Interfaces) - 1
+	}
+	machine.Status.Interfaces[index].Addresses = []string{lease.IP.String()}
+
+	if err := i.Status().Patch(ctx, machine, patch); err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+
+	i.mutex.Lock()
+	delete(i.offers, lease.MAC.String())
+	i.mutex.Unlock()
+
+	return nil
+}
+
+// recordedAddress returns the address in the range that
+// was recorded for the interface in the status of the machine.
+func (i *Inventory) recordedAddress(machine *cloudv1beta1.Machine, mac net.HardwareAddr) (netip.Addr, bool) {
+	for _, status := range machine.Status.Interfaces {
+		if !bytes.Equal(status.MAC, mac) {
+			continue
+		}
+
+		for _, address := range status.Addresses {
+			addr, err := netip.ParseAddr(address)
+			if err == nil && i.Range.Contains(addr) {
+				return addr, true
+			}
+		}
+	}
+
+	return netip.Addr{}, false
+}
+
+// staticAddress returns the first static IPv4 address of the interface.
+func staticAddress(machine *cloudv1beta1.Machine, mac net.HardwareAddr) (netip.Addr, bool) {
+	for _, iface := range machine.Spec.Interfaces {
+		if !bytes.Equal(iface.MAC, mac) {
+			continue
+		}
+
+		for _, prefix := range iface.Prefixes() {
+			if addr := prefix.Addr().Unmap(); addr.Is4() {
+				return addr, true
+			}
+		}
+	}
+
+	return netip.Addr{}, false
+}
+
+// allocate returns the lowest address in the range that is
+// neither a static address, recorded for a machine nor offered.
+func (i *Inventory) allocate(machines *cloudv1beta1.MachineList) (netip.Addr, error) {
+	used := make(map[netip.Addr]bool)
+	for _, addr := range i.offers {
+		used[addr] = true
+	}
+
+	for _, machine := range machines.Items {
+		for _, prefix := range machine.Spec.StaticAddresses() {
+			used[prefix.Addr().Unmap()] = true
+		}
+
+		for _, status := range machine.Status.Interfaces {
+			for _, address := range status.Addresses {
+				if addr, err := netip.ParseAddr(address); err == nil {
+					used[addr] = true
+				}
+			}
+		}
+	}
+
+	for addr := i.Range.Start; i.Range.Contains(addr); addr = addr.Next() {
+		if !used[addr] {
+			return addr, nil
+		}
+	}
+
+	return netip.Addr{}, fmt.Errorf("address range %s-%s is exhausted", i.Range.Start, i.Range.End)
+}
+
+// findMachine returns the machine that has an interface with the given MAC address.
+func findMachine(machines *cloudv1beta1.MachineList, mac net.HardwareAddr) *cloudv1beta1.Machine {
+	for index := range machines.Items {
+		for _, iface := range machines.Items[index].Spec.Interfaces {
+			if bytes.Equal(iface.MAC, mac) {
+				return &machines.Items[index]
+			}
+		}
+	}
+
+	return nil
+}
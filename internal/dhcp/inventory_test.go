@@ -0,0 +1,155 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dhcp
+
+import (
+	"context"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cloudv1beta1 "github.com/nicklasfrahm/cloud/api/v1beta1"
+	"github.com/nicklasfrahm/cloud/pkg/dhcp"
+)
+
+var _ = Describe("Inventory", func() {
+	var (
+		ctx       context.Context
+		k8sClient client.Client
+		inventory *Inventory
+	)
+
+	mac := func(value string) net.HardwareAddr {
+		hw, err := net.ParseMAC(value)
+		Expect(err).NotTo(HaveOccurred())
+		return hw
+	}
+
+	machine := func(name string, mac string, addresses ...string) *cloudv1beta1.Machine {
+		parsed, err := cloudv1beta1.ParseMAC(mac)
+		Expect(err).NotTo(HaveOccurred())
+
+		machine := &cloudv1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: cloudv1beta1.MachineSpec{
+				Interfaces: []cloudv1beta1.Interface{{MAC: parsed}},
+			},
+		}
+		if len(addresses) > 0 {
+			machine.Status.Interfaces = []cloudv1beta1.InterfaceStatus{{MAC: parsed, Addresses: addresses}}
+		}
+
+		return machine
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		Expect(cloudv1beta1.AddToScheme(scheme)).To(Succeed())
+
+		decommissioned := machine("wasp", "02:00:00:00:00:03")
+		decommissioned.Spec.Decommissioned = true
+
+		k8sClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&cloudv1beta1.Machine{}).
+			WithObjects(
+				machine("ant", "02:00:00:00:00:01", "10.0.0.100"),
+				machine("bee", "02:00:00:00:00:02"),
+				decommissioned,
+			).
+			Build()
+
+		addressRange, err := ParseRange("10.0.0.100-10.0.0.101")
+		Expect(err).NotTo(HaveOccurred())
+
+		inventory = &Inventory{Client: k8sClient, Range: addressRange}
+	})
+
+	It("should reuse the recorded address", func() {
+		reservation, err := inventory.Reserve(ctx, mac("02:00:00:00:00:01"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reservation.IP.String()).To(Equal("10.0.0.100"))
+		Expect(reservation.Hostname).To(Equal("ant"))
+	})
+
+	It("should allocate the lowest free address", func() {
+		reservation, err := inventory.Reserve(ctx, mac("02:00:00:00:00:02"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reservation.IP.String()).To(Equal("10.0.0.101"))
+
+		// The offer is stable until the lease is recorded.
+		reservation, err = inventory.Reserve(ctx, mac("02:00:00:00:00:02"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reservation.IP.String()).To(Equal("10.0.0.101"))
+	})
+
+	It("should reserve the static address and not allocate it", func() {
+		cow := machine("cow", "02:00:00:00:00:05")
+		cow.Spec.Interfaces[0].Addresses = []string{"fd00::101/64", "10.0.0.101/24"}
+		Expect(k8sClient.Create(ctx, cow)).To(Succeed())
+
+		_, err := inventory.Reserve(ctx, mac("02:00:00:00:00:02"))
+		Expect(err).To(MatchError(ContainSubstring("exhausted")))
+
+		reservation, err := inventory.Reserve(ctx, mac("02:00:00:00:00:05"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reservation.IP.String()).To(Equal("10.0.0.101"))
+	})
+
+	It("should ignore unknown and decommissioned machines", func() {
+		for _, hw := range []string{"02:00:00:00:00:03", "02:00:00:00:00:04"} {
+			reservation, err := inventory.Reserve(ctx, mac(hw))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reservation).To(BeNil())
+		}
+	})
+
+	It("should record the lease in the status of the machine", func() {
+		reservation, err := inventory.Reserve(ctx, mac("02:00:00:00:00:02"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(inventory.Record(ctx, dhcp.Lease{
+			MAC:     mac("02:00:00:00:00:02"),
+			IP:      reservation.IP,
+			Expires: time.Now().Add(time.Hour),
+		})).To(Succeed())
+
+		bee := &cloudv1beta1.Machine{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "bee", Namespace: "default"}, bee)).To(Succeed())
+		Expect(bee.Status.LastSeen).NotTo(BeNil())
+		Expect(bee.Status.Interfaces).To(HaveLen(1))
+		Expect(bee.Status.Interfaces[0].MAC.String()).To(Equal("02:00:00:00:00:02"))
+		Expect(bee.Status.Interfaces[0].Addresses).To(Equal([]string{"10.0.0.101"}))
+	})
+
+	It("should report an exhausted range", func() {
+		_, err := inventory.Reserve(ctx, mac("02:00:00:00:00:02"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Create(ctx, machine("cow", "02:00:00:00:00:05"))).To(Succeed())
+		_, err = inventory.Reserve(ctx, mac("02:00:00:00:00:05"))
+		Expect(err).To(MatchError(ContainSubstring("exhausted")))
+	})
+})
@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dhcp
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/nicklasfrahm/cloud/pkg/dhcp"
+)
+
+// Config configures the DHCP server of the manager.
+type Config struct {
+	// Interface is the network interface that the server listens on.
+	// The server is disabled if it is empty.
+	Interface string
+	// Range is the range of addresses that are leased to machines.
+	Range string
+	// Router is the default gateway of the network.
+	Router string
+	// DNS is a comma-separated list of DNS servers.
+	DNS string
+	// ScriptURL is the URL of the iPXE script that is sent to iPXE clients.
+	ScriptURL string
+	// TFTPServer is the address of the TFTP server that PXE clients download
+	// iPXE from. Defaults to the address of the interface, but the manager
+	// does not serve TFTP itself, e.g. use the one of "labctl serve".
+	TFTPServer string
+	// LeaseTime is the duration of a lease.
+	LeaseTime time.Duration
+}
+
+// SetupWithManager adds the DHCP server to the manager.
+func (c *Config) SetupWithManager(mgr ctrl.Manager) error {
+	addressRange, err := ParseRange(c.Range)
+	if err != nil {
+		return err
+	}
+
+	serverIP, netmask, err := interfaceAddress(c.Interface)
+	if err != nil {
+		return err
+	}
+
+	options := dhcp.Options{
+		Interface: c.Interface,
+		ServerIP:  serverIP,
+		Netmask:   netmask,
+		LeaseTime: c.LeaseTime,
+		Boot: dhcp.BootOptions{
+			ScriptURL: c.ScriptURL,
+		},
+	}
+
+	if c.Router != "" {
+		if options.Router = net.ParseIP(c.Router).To4(); options.Router == nil {
+			return fmt.Errorf("invalid router: %s", c.Router)
+		}
+	}
+
+	if c.TFTPServer != "" {
+		if options.Boot.TFTPServer = net.ParseIP(c.TFTPServer).To4(); options.Boot.TFTPServer == nil {
+			return fmt.Errorf("invalid TFTP server: %s", c.TFTPServer)
+		}
+	}
+
+	for _, server := range strings.Split(c.DNS, ",") {
+		if server = strings.TrimSpace(server); server == "" {
+			continue
+		}
+
+		ip := net.ParseIP(server).To4()
+		if ip == nil {
+			return fmt.Errorf("invalid DNS server: %s", server)
+		}
+		options.DNS = append(options.DNS, ip)
+	}
+
+	inventory := &Inventory{
+		Client: mgr.GetClient(),
+		Range:  addressRange,
+	}
+
+	server, err := dhcp.NewServer(options, inventory, mgr.GetLogger().WithName("dhcp"))
+	if err != nil {
+		return err
+	}
+
+	return mgr.Add(server)
+}
+
+// interfaceAddress returns the first IPv4 address of a network interface.
+func interfaceAddress(name string) (net.IP, net.IPMask, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find interface: %w", err)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list addresses of interface %s: %w", name, err)
+	}
+
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+			return ipNet.IP.To4(), ipNet.Mask, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("interface %s has no IPv4 address", name)
+}
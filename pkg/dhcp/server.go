@@ -0,0 +1,341 @@
+package dhcp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/server4"
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// DefaultLeaseTime is the lease time that is used if none is configured.
+const DefaultLeaseTime = time.Hour
+
+// Default boot files that are served to PXE clients via TFTP
+// to chainload iPXE, depending on the architecture of the client.
+const (
+	DefaultBootFileBIOS  = "undionly.kpxe"
+	DefaultBootFileEFI   = "ipxe.efi"
+	DefaultBootFileARM64 = "ipxe-arm64.efi"
+)
+
+// Reservation is the static lease of a client.
+type Reservation struct {
+	// IP is the address that is reserved for the client.
+	IP net.IP
+	// Hostname is sent to the client if set.
+	Hostname string
+}
+
+// Lease is a lease that was acknowledged by the server.
+type Lease struct {
+	// MAC is the hardware address of the client.
+	MAC net.HardwareAddr
+	// IP is the address that was leased to the client.
+	IP net.IP
+	// Expires is the time at which the lease expires.
+	Expires time.Time
+}
+
+// Backend provides the reservations of the server and records its leases.
+type Backend interface {
+	// Reserve returns the reservation of a client. Clients without
+	// reservation result in a nil reservation and are ignored.
+	Reserve(ctx context.Context, mac net.HardwareAddr) (*Reservation, error)
+	// Record records a lease that was acknowledged by the server.
+	Record(ctx context.Context, lease Lease) error
+}
+
+// BootOptions configure the chainloading of iPXE.
+type BootOptions struct {
+	// ScriptURL is the URL of the iPXE script that is sent to iPXE clients.
+	// Chainloading is disabled if it is empty.
+	ScriptURL string
+	// TFTPServer is the server that PXE clients download iPXE from.
+	// Defaults to the address of the server.
+	TFTPServer net.IP
+	// BIOSFile is the iPXE binary for legacy BIOS clients.
+	BIOSFile string
+	// EFIFile is the iPXE binary for x86-64 UEFI clients.
+	EFIFile string
+	// ARM64File is the iPXE binary for ARM64 UEFI clients.
+	ARM64File string
+}
+
+// Options configure the server.
+type Options struct {
+	// Interface is the network interface that the server listens on.
+	Interface string
+	// ServerIP is the address of the server on the network.
+	// It is also used as the server identifier.
+	ServerIP net.IP
+	// Netmask is the netmask of the network.
+	Netmask net.IPMask
+	// Router is the default gateway of the network.
+	Router net.IP
+	// DNS are the DNS servers of the network.
+	DNS []net.IP
+	// LeaseTime is the duration of a lease. Defaults to DefaultLeaseTime.
+	LeaseTime time.Duration
+	// Boot configures the chainloading of iPXE.
+	Boot BootOptions
+}
+
+// Server is a DHCPv4 server that only answers clients with a reservation.
+type Server struct {
+	options Options
+	backend Backend
+	logger  logr.Logger
+}
+
+// NewServer creates a new DHCPv4 server.
+func NewServer(options Options, backend Backend, logger logr.Logger) (*Server, error) {
+	if options.ServerIP.To4() == nil {
+		return nil, fmt.Errorf("server IP must be an IPv4 address: %s", options.ServerIP)
+	}
+
+	if options.LeaseTime == 0 {
+		options.LeaseTime = DefaultLeaseTime
+	}
+
+	if options.Boot.TFTPServer == nil {
+		options.Boot.TFTPServer = options.ServerIP
+	}
+
+	if options.Boot.BIOSFile == "" {
+		options.Boot.BIOSFile = DefaultBootFileBIOS
+	}
+
+	if options.Boot.EFIFile == "" {
+		options.Boot.EFIFile = DefaultBootFileEFI
+	}
+
+	if options.Boot.ARM64File == "" {
+		options.Boot.ARM64File = DefaultBootFileARM64
+	}
+
+	return &Server{
+		options: options,
+		backend: backend,
+		logger:  logger,
+	}, nil
+}
+
+// Start listens on the configured interface and serves
+// requests until the context is cancelled.
+func (s *Server) Start(ctx context.Context) error {
+	server, err := server4.NewServer(s.options.Interface, nil, func(conn net.PacketConn, peer net.Addr, req *dhcpv4.DHCPv4) {
+		s.serve(ctx, conn, peer, req)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	s.logger.Info("Serving DHCP", "interface", s.options.Interface, "address", s.options.ServerIP.String())
+
+	if err := server.Serve(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+
+	return nil
+}
+
+// NeedLeaderElection ensures that only a single replica
+// answers requests if the server runs in a manager.
+func (s *Server) NeedLeaderElection() bool {
+	return true
+}
+
+// serve handles a single request and sends the reply.
+func (s *Server) serve(ctx context.Context, conn net.PacketConn, peer net.Addr, req *dhcpv4.DHCPv4) {
+	logger := s.logger.WithValues("mac", req.ClientHWAddr.String(), "type", req.MessageType().String())
+
+	reply, err := s.Handle(ctx, req)
+	if err != nil {
+		logger.Error(err, "Failed to handle request")
+		return
+	}
+
+	if reply == nil {
+		logger.V(1).Info("Ignored request")
+		return
+	}
+
+	// Replies to relayed requests are sent to the relay agent.
+	if !req.GatewayIPAddr.IsUnspecified() {
+		peer = &net.UDPAddr{IP: req.GatewayIPAddr, Port: dhcpv4.ServerPort}
+	}
+
+	if _, err := conn.WriteTo(reply.ToBytes(), peer); err != nil {
+		logger.Error(err, "Failed to send reply")
+		return
+	}
+
+	logger.Info("Sent reply", "reply", reply.MessageType().String(), "ip", reply.YourIPAddr.String())
+}
+
+// Handle returns the reply to a request. Requests that
+// must not be answered by the server result in a nil reply.
+func (s *Server) Handle(ctx context.Context, req *dhcpv4.DHCPv4) (*dhcpv4.DHCPv4, error) {
+	if req.OpCode != dhcpv4.OpcodeBootRequest {
+		return nil, nil
+	}
+
+	switch req.MessageType() {
+	case dhcpv4.MessageTypeDiscover:
+		return s.offer(ctx, req)
+	case dhcpv4.MessageTypeRequest:
+		return s.acknowledge(ctx, req)
+	default:
+		return nil, nil
+	}
+}
+
+// offer answers a DISCOVER with the reservation of the client.
+func (s *Server) offer(ctx context.Context, req *dhcpv4.DHCPv4) (*dhcpv4.DHCPv4, error) {
+	reservation, err := s.backend.Reserve(ctx, req.ClientHWAddr)
+	if err != nil || reservation == nil {
+		return nil, err
+	}
+
+	return s.reply(req, dhcpv4.MessageTypeOffer, reservation)
+}
+
+// acknowledge answers a REQUEST. The lease is only acknowledged
+// if the requested address matches the reservation of the client.
+func (s *Server) acknowledge(ctx context.Context, req *dhcpv4.DHCPv4) (*dhcpv4.DHCPv4, error) {
+	// The client selected the offer of another server.
+	if id := req.ServerIdentifier(); id != nil && !id.Equal(s.options.ServerIP) {
+		return nil, nil
+	}
+
+	reservation, err := s.backend.Reserve(ctx, req.ClientHWAddr)
+	if err != nil || reservation == nil {
+		return nil, err
+	}
+
+	requested := req.RequestedIPAddress()
+	if requested == nil {
+		requested = req.ClientIPAddr
+	}
+
+	if !requested.Equal(reservation.IP) {
+		return dhcpv4.NewReplyFromRequest(req,
+			dhcpv4.WithMessageType(dhcpv4.MessageTypeNak),
+			dhcpv4.WithOption(dhcpv4.OptServerIdentifier(s.options.ServerIP)),
+			dhcpv4.WithOption(dhcpv4.OptMessage(fmt.Sprintf("requested address is not reserved: %s", requested))),
+		)
+	}
+
+	reply, err := s.reply(req, dhcpv4.MessageTypeAck, reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	lease := Lease{
+		MAC:     req.ClientHWAddr,
+		IP:      reservation.IP,
+		Expires: time.Now().Add(s.options.LeaseTime),
+	}
+	if err := s.backend.Record(ctx, lease); err != nil {
+		return nil, fmt.Errorf("failed to record lease: %w", err)
+	}
+
+	return reply, nil
+}
+
+// reply creates a reply that assigns the reservation to the client.
+func (s *Server) reply(req *dhcpv4.DHCPv4, messageType dhcpv4.MessageType, reservation *Reservation) (*dhcpv4.DHCPv4, error) {
+	modifiers := []dhcpv4.Modifier{
+		dhcpv4.WithMessageType(messageType),
+		dhcpv4.WithYourIP(reservation.IP),
+		dhcpv4.WithOption(dhcpv4.OptServerIdentifier(s.options.ServerIP)),
+		dhcpv4.WithOption(dhcpv4.OptIPAddressLeaseTime(s.options.LeaseTime)),
+	}
+
+	if s.options.Netmask != nil {
+		modifiers = append(modifiers, dhcpv4.WithNetmask(s.options.Netmask))
+	}
+
+	if s.options.Router != nil {
+		modifiers = append(modifiers, dhcpv4.WithRouter(s.options.Router))
+	}
+
+	if len(s.options.DNS) > 0 {
+		modifiers = append(modifiers, dhcpv4.WithDNS(s.options.DNS...))
+	}
+
+	if reservation.Hostname != "" {
+		modifiers = append(modifiers, dhcpv4.WithOption(dhcpv4.OptHostName(reservation.Hostname)))
+	}
+
+	reply, err := dhcpv4.NewReplyFromRequest(req, modifiers...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reply: %w", err)
+	}
+
+	s.boot(req, reply)
+
+	return reply, nil
+}
+
+// boot adds the options that chainload iPXE to the reply. Clients that
+// already run iPXE receive the script URL, while PXE firmware receives
+// the iPXE binary for its architecture via TFTP.
+func (s *Server) boot(req *dhcpv4.DHCPv4, reply *dhcpv4.DHCPv4) {
+	boot := s.options.Boot
+	if boot.ScriptURL == "" {
+		return
+	}
+
+	if slices.Contains(req.UserClass(), "iPXE") {
+		reply.BootFileName = boot.ScriptURL
+		reply.UpdateOption(dhcpv4.OptBootFileName(boot.ScriptURL))
+		return
+	}
+
+	if !strings.HasPrefix(req.ClassIdentifier(), "PXEClient") {
+		return
+	}
+
+	file, err := s.bootFile(req.ClientArch())
+	if err != nil {
+		s.logger.Info("Unable to chainload iPXE", "mac", req.ClientHWAddr.String(), "reason", err.Error())
+		return
+	}
+
+	reply.ServerIPAddr = boot.TFTPServer
+	reply.BootFileName = file
+	reply.UpdateOption(dhcpv4.OptTFTPServerName(boot.TFTPServer.String()))
+	reply.UpdateOption(dhcpv4.OptBootFileName(file))
+}
+
+// bootFile returns the iPXE binary for the architecture of a PXE client.
+func (s *Server) bootFile(archs []iana.Arch) (string, error) {
+	// Clients that don't send their architecture are legacy BIOS clients.
+	if len(archs) == 0 {
+		return s.options.Boot.BIOSFile, nil
+	}
+
+	switch archs[0] {
+	case iana.INTEL_X86PC:
+		return s.options.Boot.BIOSFile, nil
+	case iana.EFI_X86_64, iana.EFI_BC:
+		return s.options.Boot.EFIFile, nil
+	case iana.EFI_ARM64:
+		return s.options.Boot.ARM64File, nil
+	default:
+		return "", fmt.Errorf("unsupported architecture: %s", archs[0])
+	}
+}
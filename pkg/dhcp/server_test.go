@@ -0,0 +1,246 @@
+package dhcp
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/iana"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeBackend reserves addresses from a static map and remembers its leases.
+type fakeBackend struct {
+	reservations map[string]Reservation
+	leases       []Lease
+}
+
+func (b *fakeBackend) Reserve(_ context.Context, mac net.HardwareAddr) (*Reservation, error) {
+	reservation, ok := b.reservations[mac.String()]
+	if !ok {
+		return nil, nil
+	}
+
+	return &reservation, nil
+}
+
+func (b *fakeBackend) Record(_ context.Context, lease Lease) error {
+	b.leases = append(b.leases, lease)
+	return nil
+}
+
+// recordingConn records the destinations of the packets written to it.
+type recordingConn struct {
+	net.PacketConn
+	peers []net.Addr
+}
+
+func (c *recordingConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	c.peers = append(c.peers, addr)
+	return c.PacketConn.WriteTo(p, addr)
+}
+
+var _ = Describe("Server", func() {
+	var (
+		ctx     context.Context
+		backend *fakeBackend
+		server  *Server
+		known   net.HardwareAddr
+	)
+
+	serverIP := net.IPv4(10, 0, 0, 1).To4()
+	reservedIP := net.IPv4(10, 0, 0, 100).To4()
+	scriptURL := "http://10.0.0.1:8080/v1beta1/boot/index.ipxe"
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		known, _ = net.ParseMAC("32:de:fa:97:71:4f")
+		backend = &fakeBackend{
+			reservations: map[string]Reservation{
+				known.String(): {IP: reservedIP, Hostname: "bee"},
+			},
+		}
+
+		var err error
+		server, err = NewServer(Options{
+			ServerIP: serverIP,
+			Netmask:  net.CIDRMask(24, 32),
+			Router:   serverIP,
+			Boot: BootOptions{
+				ScriptURL: scriptURL,
+			},
+		}, backend, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	discover := func(mac net.HardwareAddr, modifiers ...dhcpv4.Modifier) *dhcpv4.DHCPv4 {
+		req, err := dhcpv4.NewDiscovery(mac, modifiers...)
+		Expect(err).NotTo(HaveOccurred())
+		return req
+	}
+
+	It("should offer the reserved address", func() {
+		offer, err := server.Handle(ctx, discover(known))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offer).NotTo(BeNil())
+		Expect(offer.MessageType()).To(Equal(dhcpv4.MessageTypeOffer))
+		Expect(offer.YourIPAddr.Equal(reservedIP)).To(BeTrue())
+		Expect(offer.ServerIdentifier().Equal(serverIP)).To(BeTrue())
+		Expect(offer.HostName()).To(Equal("bee"))
+		Expect(offer.SubnetMask()).To(Equal(net.CIDRMask(24, 32)))
+		Expect(offer.BootFileName).To(BeEmpty())
+		Expect(backend.leases).To(BeEmpty())
+	})
+
+	It("should ignore unknown clients", func() {
+		unknown, _ := net.ParseMAC("02:00:00:00:00:01")
+		offer, err := server.Handle(ctx, discover(unknown))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offer).To(BeNil())
+	})
+
+	It("should acknowledge and record a request for the reserved address", func() {
+		offer, err := server.Handle(ctx, discover(known))
+		Expect(err).NotTo(HaveOccurred())
+
+		req, err := dhcpv4.NewRequestFromOffer(offer)
+		Expect(err).NotTo(HaveOccurred())
+
+		ack, err := server.Handle(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ack.MessageType()).To(Equal(dhcpv4.MessageTypeAck))
+		Expect(ack.YourIPAddr.Equal(reservedIP)).To(BeTrue())
+		Expect(backend.leases).To(HaveLen(1))
+		Expect(backend.leases[0].MAC).To(Equal(known))
+		Expect(backend.leases[0].IP.Equal(reservedIP)).To(BeTrue())
+	})
+
+	It("should reject a request for another address", func() {
+		req := discover(known,
+			dhcpv4.WithMessageType(dhcpv4.MessageTypeRequest),
+			dhcpv4.WithOption(dhcpv4.OptRequestedIPAddress(net.IPv4(10, 0, 0, 50))),
+		)
+
+		nak, err := server.Handle(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nak.MessageType()).To(Equal(dhcpv4.MessageTypeNak))
+		Expect(backend.leases).To(BeEmpty())
+	})
+
+	It("should ignore requests for other servers", func() {
+		req := discover(known,
+			dhcpv4.WithMessageType(dhcpv4.MessageTypeRequest),
+			dhcpv4.WithOption(dhcpv4.OptRequestedIPAddress(reservedIP)),
+			dhcpv4.WithOption(dhcpv4.OptServerIdentifier(net.IPv4(10, 0, 0, 2))),
+		)
+
+		reply, err := server.Handle(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reply).To(BeNil())
+		Expect(backend.leases).To(BeEmpty())
+	})
+
+	DescribeTable("should chainload iPXE via TFTP",
+		func(arch iana.Arch, file string) {
+			offer, err := server.Handle(ctx, discover(known,
+				dhcpv4.WithOption(dhcpv4.OptClassIdentifier("PXEClient:Arch:00000:UNDI:002001")),
+				dhcpv4.WithOption(dhcpv4.OptClientArch(arch)),
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offer.BootFileName).To(Equal(file))
+			Expect(offer.BootFileNameOption()).To(Equal(file))
+			Expect(offer.ServerIPAddr.Equal(serverIP)).To(BeTrue())
+			Expect(offer.TFTPServerName()).To(Equal(serverIP.String()))
+		},
+		Entry("BIOS", iana.INTEL_X86PC, DefaultBootFileBIOS),
+		Entry("UEFI x86-64", iana.EFI_X86_64, DefaultBootFileEFI),
+		Entry("UEFI ARM64", iana.EFI_ARM64, DefaultBootFileARM64),
+	)
+
+	It("should use the configured TFTP server", func() {
+		tftpServer := net.IPv4(10, 0, 0, 2).To4()
+		server, err := NewServer(Options{
+			ServerIP: serverIP,
+			Boot:     BootOptions{ScriptURL: scriptURL, TFTPServer: tftpServer},
+		}, backend, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+
+		offer, err := server.Handle(ctx, discover(known,
+			dhcpv4.WithOption(dhcpv4.OptClassIdentifier("PXEClient:Arch:00000:UNDI:002001")),
+		))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offer.ServerIPAddr.Equal(tftpServer)).To(BeTrue())
+		Expect(offer.TFTPServerName()).To(Equal(tftpServer.String()))
+	})
+
+	It("should send the script URL to iPXE clients", func() {
+		offer, err := server.Handle(ctx, discover(known,
+			dhcpv4.WithOption(dhcpv4.OptClassIdentifier("PXEClient:Arch:00007:UNDI:003016")),
+			dhcpv4.WithOption(dhcpv4.OptClientArch(iana.EFI_X86_64)),
+			dhcpv4.WithOption(dhcpv4.OptUserClass("iPXE")),
+		))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offer.BootFileName).To(Equal(scriptURL))
+		Expect(offer.BootFileNameOption()).To(Equal(scriptURL))
+	})
+
+	Describe("serve", func() {
+		var (
+			conn   *recordingConn
+			client net.PacketConn
+		)
+
+		BeforeEach(func() {
+			serverConn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			conn = &recordingConn{PacketConn: serverConn}
+
+			client, err = net.ListenPacket("udp4", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.SetReadDeadline(time.Now().Add(5 * time.Second))).To(Succeed())
+		})
+
+		AfterEach(func() {
+			conn.Close()
+			client.Close()
+		})
+
+		// receive reads a reply from the client socket.
+		receive := func() *dhcpv4.DHCPv4 {
+			buffer := make([]byte, 1500)
+			n, _, err := client.ReadFrom(buffer)
+			Expect(err).NotTo(HaveOccurred())
+
+			reply, err := dhcpv4.FromBytes(buffer[:n])
+			Expect(err).NotTo(HaveOccurred())
+			return reply
+		}
+
+		It("should send the reply to the peer", func() {
+			server.serve(ctx, conn, client.LocalAddr(), discover(known))
+
+			reply := receive()
+			Expect(reply.MessageType()).To(Equal(dhcpv4.MessageTypeOffer))
+			Expect(reply.YourIPAddr.Equal(reservedIP)).To(BeTrue())
+			Expect(conn.peers).To(Equal([]net.Addr{client.LocalAddr()}))
+		})
+
+		It("should send the reply of a relayed request to the relay agent", func() {
+			relay := net.IPv4(127, 0, 0, 1).To4()
+			server.serve(ctx, conn, client.LocalAddr(), discover(known, dhcpv4.WithGatewayIP(relay)))
+
+			Expect(conn.peers).To(HaveLen(1))
+			Expect(conn.peers[0]).To(Equal(&net.UDPAddr{IP: relay, Port: dhcpv4.ServerPort}))
+		})
+
+		It("should not reply to unknown clients", func() {
+			unknown, _ := net.ParseMAC("02:00:00:00:00:01")
+			server.serve(ctx, conn, client.LocalAddr(), discover(unknown))
+
+			Expect(conn.peers).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,98 @@
+package power
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// DefaultIPMIPort is the port of IPMI over LAN.
+const DefaultIPMIPort = "623"
+
+// IPMI controls the power of a machine via IPMI over LAN. It invokes
+// ipmitool with the lanplus interface, which must be installed.
+type IPMI struct {
+	host        string
+	port        string
+	credentials Credentials
+
+	// Command is the path of ipmitool. Defaults to "ipmitool".
+	Command string
+}
+
+// NewIPMI returns a driver for the BMC at the endpoint,
+// which is a host with an optional port, e.g. "10.0.0.10:623".
+func NewIPMI(endpoint string, credentials Credentials) (*IPMI, error) {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		host, port = endpoint, DefaultIPMIPort
+	}
+
+	if host == "" {
+		return nil, fmt.Errorf("invalid endpoint: %s", endpoint)
+	}
+
+	return &IPMI{
+		host:        host,
+		port:        port,
+		credentials: credentials,
+		Command:     "ipmitool",
+	}, nil
+}
+
+// State returns the power state of the chassis.
+func (i *IPMI) State(ctx context.Context) (State, error) {
+	output, err := i.run(ctx, "chassis", "power", "status")
+	if err != nil {
+		return StateUnknown, err
+	}
+
+	switch {
+	case strings.HasSuffix(output, " is on"):
+		return StateOn, nil
+	case strings.HasSuffix(output, " is off"):
+		return StateOff, nil
+	default:
+		return StateUnknown, nil
+	}
+}
+
+// On powers on the chassis.
+func (i *IPMI) On(ctx context.Context) error {
+	_, err := i.run(ctx, "chassis", "power", "on")
+	return err
+}
+
+// Off powers off the chassis without shutting down the operating system.
+func (i *IPMI) Off(ctx context.Context) error {
+	_, err := i.run(ctx, "chassis", "power", "off")
+	return err
+}
+
+// Cycle powers off the chassis and powers it on again.
+func (i *IPMI) Cycle(ctx context.Context) error {
+	_, err := i.run(ctx, "chassis", "power", "cycle")
+	return err
+}
+
+// run invokes ipmitool with the arguments and returns its trimmed output.
+// The password is passed via the environment to hide it from other users.
+func (i *IPMI) run(ctx context.Context, args ...string) (string, error) {
+	args = append([]string{"-I", "lanplus", "-H", i.host, "-p", i.port, "-U", i.credentials.Username, "-E"}, args...)
+
+	cmd := exec.CommandContext(ctx, i.Command, args...)
+	cmd.Env = append(os.Environ(), "IPMI_PASSWORD="+i.credentials.Password)
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to run ipmitool: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.ToLower(strings.TrimSpace(stdout.String())), nil
+}
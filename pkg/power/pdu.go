@@ -0,0 +1,133 @@
+package power
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultCycleDelay is the time that an outlet is
+// powered off before it is powered on again.
+const DefaultCycleDelay = 5 * time.Second
+
+// PDU controls the power of a machine via the outlet of a smart PDU that
+// implements the HTTP API of Tasmota, which many smart plugs and PDUs do.
+// Reference: https://tasmota.github.io/docs/Commands/#with-web-requests
+type PDU struct {
+	endpoint    *url.URL
+	outlet      int
+	credentials Credentials
+	client      *http.Client
+
+	// CycleDelay is the time that the outlet is powered off
+	// during a power cycle. Defaults to DefaultCycleDelay.
+	CycleDelay time.Duration
+}
+
+// NewPDU returns a driver for the outlet of the PDU at the endpoint.
+func NewPDU(endpoint string, outlet int, credentials Credentials, client *http.Client) (*PDU, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint: %s", endpoint)
+	}
+
+	if outlet == 0 {
+		outlet = 1
+	}
+
+	return &PDU{
+		endpoint:    parsed,
+		outlet:      outlet,
+		credentials: credentials,
+		client:      client,
+		CycleDelay:  DefaultCycleDelay,
+	}, nil
+}
+
+// State returns the power state of the outlet.
+func (p *PDU) State(ctx context.Context) (State, error) {
+	return p.command(ctx, "")
+}
+
+// On powers on the outlet.
+func (p *PDU) On(ctx context.Context) error {
+	_, err := p.command(ctx, "On")
+	return err
+}
+
+// Off powers off the outlet.
+func (p *PDU) Off(ctx context.Context) error {
+	_, err := p.command(ctx, "Off")
+	return err
+}
+
+// Cycle powers off the outlet and powers it on again after the delay.
+func (p *PDU) Cycle(ctx context.Context) error {
+	if err := p.Off(ctx); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(p.CycleDelay):
+	}
+
+	return p.On(ctx)
+}
+
+// command sends the power command with the argument for the outlet and
+// returns the resulting power state, e.g. {"POWER2":"ON"} for outlet 2.
+func (p *PDU) command(ctx context.Context, argument string) (State, error) {
+	name := fmt.Sprintf("Power%d", p.outlet)
+
+	query := url.Values{}
+	query.Set("cmnd", strings.TrimSpace(name+" "+argument))
+	if p.credentials.Username != "" {
+		query.Set("user", p.credentials.Username)
+		query.Set("password", p.credentials.Password)
+	}
+
+	target := p.endpoint.ResolveReference(&url.URL{Path: "/cm", RawQuery: query.Encode()})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return StateUnknown, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return StateUnknown, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return StateUnknown, fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	result := make(map[string]any)
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return StateUnknown, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Devices with a single outlet omit the number of the outlet.
+	value, ok := result[strings.ToUpper(name)]
+	if !ok && p.outlet == 1 {
+		value, ok = result["POWER"]
+	}
+	if !ok {
+		return StateUnknown, fmt.Errorf("outlet %d not found", p.outlet)
+	}
+
+	switch value {
+	case "ON":
+		return StateOn, nil
+	case "OFF":
+		return StateOff, nil
+	default:
+		return StateUnknown, nil
+	}
+}
@@ -0,0 +1,57 @@
+package power
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PDU", func() {
+	var (
+		ctx      context.Context
+		server   *httptest.Server
+		outlets  map[string]string
+		commands []string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		outlets = map[string]string{"POWER1": "ON", "POWER2": "OFF"}
+		commands = nil
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			command := r.URL.Query().Get("cmnd")
+			commands = append(commands, command)
+
+			name, argument, _ := strings.Cut(command, " ")
+			name = strings.ToUpper(name)
+			if argument != "" {
+				outlets[name] = strings.ToUpper(argument)
+			}
+
+			fmt.Fprintf(w, `{%q:%q}`, name, outlets[name])
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should control the outlet", func() {
+		driver, err := NewPDU(server.URL, 2, Credentials{}, http.DefaultClient)
+		Expect(err).NotTo(HaveOccurred())
+		driver.CycleDelay = time.Millisecond
+
+		Expect(driver.State(ctx)).To(Equal(StateOff))
+		Expect(driver.On(ctx)).To(Succeed())
+		Expect(driver.State(ctx)).To(Equal(StateOn))
+		Expect(driver.Cycle(ctx)).To(Succeed())
+		Expect(commands).To(Equal([]string{"Power2", "Power2 On", "Power2", "Power2 Off", "Power2 On"}))
+	})
+})
@@ -0,0 +1,99 @@
+package power
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout is the timeout of requests to power controllers.
+const DefaultTimeout = 30 * time.Second
+
+// State is the power state of a machine.
+type State string
+
+const (
+	// StateOn means that the machine is powered on.
+	StateOn State = "On"
+	// StateOff means that the machine is powered off.
+	StateOff State = "Off"
+	// StateUnknown means that the power state could not be observed.
+	StateUnknown State = "Unknown"
+)
+
+// Names of the supported drivers.
+const (
+	DriverRedfish = "redfish"
+	DriverIPMI    = "ipmi"
+	DriverWOL     = "wol"
+	DriverPDU     = "pdu"
+)
+
+// ErrUnsupported is returned by drivers for operations that they do not support,
+// e.g. if the power state of a machine that is woken via Wake-on-LAN is requested.
+var ErrUnsupported = errors.New("operation not supported by driver")
+
+// Driver controls the power of a machine.
+type Driver interface {
+	// State returns the power state of the machine.
+	State(ctx context.Context) (State, error)
+	// On powers on the machine.
+	On(ctx context.Context) error
+	// Off powers off the machine immediately.
+	Off(ctx context.Context) error
+	// Cycle powers off the machine and powers it on again.
+	Cycle(ctx context.Context) error
+}
+
+// Credentials authenticate with a power controller.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Options configure a driver.
+type Options struct {
+	// Driver is the name of the driver.
+	Driver string
+	// Endpoint is the address of the power controller, whose format depends on the driver.
+	Endpoint string
+	// Outlet is the outlet of the PDU. Defaults to 1.
+	Outlet int
+	// Credentials authenticate with the power controller.
+	Credentials Credentials
+	// InsecureSkipVerify disables the verification of TLS certificates.
+	InsecureSkipVerify bool
+	// MACs are the MAC addresses of the interfaces that are woken via Wake-on-LAN.
+	MACs []net.HardwareAddr
+}
+
+// NewDriver returns the driver that is configured by the options.
+func NewDriver(options Options) (Driver, error) {
+	switch options.Driver {
+	case DriverRedfish:
+		return NewRedfish(options.Endpoint, options.Credentials, httpClient(options.InsecureSkipVerify))
+	case DriverIPMI:
+		return NewIPMI(options.Endpoint, options.Credentials)
+	case DriverWOL:
+		return NewWOL(options.Endpoint, options.MACs)
+	case DriverPDU:
+		return NewPDU(options.Endpoint, options.Outlet, options.Credentials, httpClient(options.InsecureSkipVerify))
+	default:
+		return nil, fmt.Errorf("unsupported driver: %s", options.Driver)
+	}
+}
+
+// httpClient returns the HTTP client of the drivers.
+func httpClient(insecureSkipVerify bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   DefaultTimeout,
+	}
+}
@@ -0,0 +1,173 @@
+package power
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// redfishSystems is the path of the collection of the systems of a BMC.
+const redfishSystems = "/redfish/v1/Systems"
+
+// Redfish controls the power of a system via the Redfish API of its BMC.
+// Reference: https://www.dmtf.org/standards/redfish
+type Redfish struct {
+	endpoint    *url.URL
+	credentials Credentials
+	client      *http.Client
+
+	mutex  sync.Mutex
+	system string
+}
+
+// NewRedfish returns a driver for the BMC at the endpoint. If the endpoint is
+// the URL of a system, e.g. "https://10.0.0.10/redfish/v1/Systems/1", the
+// system is controlled. Otherwise, the first system of the BMC is controlled.
+func NewRedfish(endpoint string, credentials Credentials, client *http.Client) (*Redfish, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint: %s", endpoint)
+	}
+
+	redfish := &Redfish{
+		endpoint:    parsed,
+		credentials: credentials,
+		client:      client,
+	}
+
+	if path := strings.TrimSuffix(parsed.Path, "/"); strings.HasPrefix(path, redfishSystems+"/") {
+		redfish.system = path
+	}
+
+	return redfish, nil
+}
+
+// State returns the power state of the system. Systems that
+// are powering on or off are reported in their target state.
+func (r *Redfish) State(ctx context.Context) (State, error) {
+	system, err := r.systemPath(ctx)
+	if err != nil {
+		return StateUnknown, err
+	}
+
+	resource := struct {
+		PowerState string `json:"PowerState"`
+	}{}
+	if err := r.do(ctx, http.MethodGet, system, nil, &resource); err != nil {
+		return StateUnknown, err
+	}
+
+	switch resource.PowerState {
+	case "On", "PoweringOn":
+		return StateOn, nil
+	case "Off", "PoweringOff":
+		return StateOff, nil
+	default:
+		return StateUnknown, nil
+	}
+}
+
+// On powers on the system.
+func (r *Redfish) On(ctx context.Context) error {
+	return r.reset(ctx, "On")
+}
+
+// Off powers off the system without shutting down the operating system.
+func (r *Redfish) Off(ctx context.Context) error {
+	return r.reset(ctx, "ForceOff")
+}
+
+// Cycle powers off the system and powers it on again.
+func (r *Redfish) Cycle(ctx context.Context) error {
+	return r.reset(ctx, "PowerCycle")
+}
+
+// reset invokes the reset action of the system with the reset type.
+func (r *Redfish) reset(ctx context.Context, resetType string) error {
+	system, err := r.systemPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.do(ctx, http.MethodPost, system+"/Actions/ComputerSystem.Reset", map[string]string{"ResetType": resetType}, nil)
+}
+
+// systemPath returns the path of the controlled system,
+// which is discovered on the first call if not configured.
+func (r *Redfish) systemPath(ctx context.Context) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.system != "" {
+		return r.system, nil
+	}
+
+	collection := struct {
+		Members []struct {
+			ID string `json:"@odata.id"`
+		} `json:"Members"`
+	}{}
+	if err := r.do(ctx, http.MethodGet, redfishSystems, nil, &collection); err != nil {
+		return "", err
+	}
+
+	if len(collection.Members) == 0 || collection.Members[0].ID == "" {
+		return "", fmt.Errorf("no system found at %s", r.endpoint.Host)
+	}
+
+	r.system = strings.TrimSuffix(collection.Members[0].ID, "/")
+
+	return r.system, nil
+}
+
+// do sends a request to the BMC and decodes the response into the output, if any.
+func (r *Redfish) do(ctx context.Context, method string, path string, input any, output any) error {
+	var body io.Reader
+	if input != nil {
+		data, err := json.Marshal(input)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	target := r.endpoint.ResolveReference(&url.URL{Path: path})
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.credentials.Username != "" {
+		req.SetBasicAuth(r.credentials.Username, r.credentials.Password)
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s for %s %s", res.Status, method, path)
+	}
+
+	if output == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(output); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
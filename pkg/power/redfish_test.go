@@ -0,0 +1,127 @@
+package power
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeRedfish is a BMC with a single system that implements the
+// parts of the Redfish API that are used by the driver.
+type fakeRedfish struct {
+	mutex      sync.Mutex
+	powerState string
+	resets     []string
+}
+
+// ServeHTTP implements http.Handler.
+func (f *fakeRedfish) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/Systems":
+		json.NewEncoder(w).Encode(map[string]any{
+			"Members": []map[string]string{{"@odata.id": "/redfish/v1/Systems/System.Embedded.1"}},
+		})
+	case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/Systems/System.Embedded.1":
+		json.NewEncoder(w).Encode(map[string]string{"PowerState": f.powerState})
+	case r.Method == http.MethodPost && r.URL.Path == "/redfish/v1/Systems/System.Embedded.1/Actions/ComputerSystem.Reset":
+		body := struct{ ResetType string }{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		f.resets = append(f.resets, body.ResetType)
+		switch body.ResetType {
+		case "On", "PowerCycle":
+			f.powerState = "On"
+		case "ForceOff":
+			f.powerState = "Off"
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+var _ = Describe("Redfish", func() {
+	var (
+		ctx    context.Context
+		bmc    *fakeRedfish
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		bmc = &fakeRedfish{powerState: "Off"}
+		server = httptest.NewTLSServer(bmc)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newDriver := func(endpoint string, credentials Credentials) Driver {
+		driver, err := NewDriver(Options{
+			Driver:             DriverRedfish,
+			Endpoint:           endpoint,
+			Credentials:        credentials,
+			InsecureSkipVerify: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		return driver
+	}
+
+	It("should discover the system and change its power state", func() {
+		driver := newDriver(server.URL, Credentials{Username: "admin", Password: "secret"})
+		Expect(driver.State(ctx)).To(Equal(StateOff))
+
+		Expect(driver.On(ctx)).To(Succeed())
+		Expect(driver.State(ctx)).To(Equal(StateOn))
+
+		Expect(driver.Off(ctx)).To(Succeed())
+		Expect(driver.State(ctx)).To(Equal(StateOff))
+
+		Expect(driver.Cycle(ctx)).To(Succeed())
+		Expect(bmc.resets).To(Equal([]string{"On", "ForceOff", "PowerCycle"}))
+	})
+
+	It("should use the system of the endpoint", func() {
+		driver := newDriver(server.URL+"/redfish/v1/Systems/System.Embedded.1/", Credentials{Username: "admin", Password: "secret"})
+		Expect(driver.On(ctx)).To(Succeed())
+		Expect(bmc.resets).To(Equal([]string{"On"}))
+	})
+
+	It("should report systems that are powering on in their target state", func() {
+		bmc.powerState = "PoweringOn"
+		driver := newDriver(server.URL, Credentials{Username: "admin", Password: "secret"})
+		Expect(driver.State(ctx)).To(Equal(StateOn))
+	})
+
+	It("should fail with invalid credentials", func() {
+		driver := newDriver(server.URL, Credentials{Username: "admin", Password: "wrong"})
+		_, err := driver.State(ctx)
+		Expect(err).To(MatchError(ContainSubstring("401")))
+	})
+
+	It("should verify the certificate of the BMC by default", func() {
+		driver, err := NewDriver(Options{Driver: DriverRedfish, Endpoint: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = driver.State(ctx)
+		Expect(err).To(HaveOccurred())
+	})
+})
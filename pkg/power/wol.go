@@ -0,0 +1,65 @@
+package power
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/nicklasfrahm/cloud/pkg/wol"
+)
+
+// WOL powers on a machine via Wake-on-LAN. As magic packets can neither
+// power off a machine nor observe its power state, all other operations
+// return ErrUnsupported.
+type WOL struct {
+	address string
+	macs    []net.HardwareAddr
+}
+
+// NewWOL returns a driver that sends magic packets for the interfaces to the
+// broadcast address at the endpoint, which defaults to "255.255.255.255:9".
+func NewWOL(endpoint string, macs []net.HardwareAddr) (*WOL, error) {
+	if len(macs) == 0 {
+		return nil, fmt.Errorf("at least one interface is required")
+	}
+
+	if endpoint == "" {
+		endpoint = wol.DefaultAddress
+	}
+
+	return &WOL{
+		address: endpoint,
+		macs:    macs,
+	}, nil
+}
+
+// State is not supported.
+func (w *WOL) State(ctx context.Context) (State, error) {
+	return StateUnknown, ErrUnsupported
+}
+
+// On sends a magic packet to every interface.
+func (w *WOL) On(ctx context.Context) error {
+	for _, mac := range w.macs {
+		packet, err := wol.MagicPacket(mac, nil)
+		if err != nil {
+			return err
+		}
+
+		if err := wol.SendUDP(w.address, packet); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Off is not supported.
+func (w *WOL) Off(ctx context.Context) error {
+	return ErrUnsupported
+}
+
+// Cycle is not supported.
+func (w *WOL) Cycle(ctx context.Context) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,113 @@
+package tftp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Opcodes of the packets as defined in RFC 1350 and RFC 2347.
+const (
+	opRRQ   uint16 = 1
+	opWRQ   uint16 = 2
+	opDATA  uint16 = 3
+	opACK   uint16 = 4
+	opERROR uint16 = 5
+	opOACK  uint16 = 6
+)
+
+// ErrorCode is the code of an ERROR packet.
+type ErrorCode uint16
+
+// Error codes as defined in RFC 1350 and RFC 2347.
+const (
+	ErrorNotDefined        ErrorCode = 0
+	ErrorFileNotFound      ErrorCode = 1
+	ErrorAccessViolation   ErrorCode = 2
+	ErrorIllegalOperation  ErrorCode = 4
+	ErrorUnknownTransferID ErrorCode = 5
+	ErrorOptionNegotiation ErrorCode = 8
+)
+
+// readRequest is a parsed read request.
+type readRequest struct {
+	filename string
+	mode     string
+	options  map[string]string
+}
+
+// parseRequest parses the body of a RRQ or WRQ packet, which consists of
+// null-terminated strings: filename, mode and pairs of options and values.
+func parseRequest(body []byte) (*readRequest, error) {
+	fields := bytes.Split(body, []byte{0})
+	if len(fields) < 3 || len(fields[len(fields)-1]) != 0 {
+		return nil, errors.New("malformed request")
+	}
+
+	// Drop the empty field after the final terminator.
+	fields = fields[:len(fields)-1]
+	if len(fields)%2 != 0 {
+		return nil, errors.New("malformed options")
+	}
+
+	request := &readRequest{
+		filename: string(fields[0]),
+		mode:     strings.ToLower(string(fields[1])),
+		options:  make(map[string]string),
+	}
+
+	for index := 2; index < len(fields); index += 2 {
+		request.options[strings.ToLower(string(fields[index]))] = string(fields[index+1])
+	}
+
+	return request, nil
+}
+
+// dataPacket encodes a DATA packet.
+func dataPacket(block uint16, data []byte) []byte {
+	packet := make([]byte, 4, 4+len(data))
+	binary.BigEndian.PutUint16(packet[0:], opDATA)
+	binary.BigEndian.PutUint16(packet[2:], block)
+	return append(packet, data...)
+}
+
+// oackPacket encodes an OACK packet with the given options in order.
+func oackPacket(options [][2]string) []byte {
+	packet := binary.BigEndian.AppendUint16(nil, opOACK)
+	for _, option := range options {
+		packet = append(packet, option[0]...)
+		packet = append(packet, 0)
+		packet = append(packet, option[1]...)
+		packet = append(packet, 0)
+	}
+	return packet
+}
+
+// errorPacket encodes an ERROR packet.
+func errorPacket(code ErrorCode, message string) []byte {
+	packet := make([]byte, 4, 4+len(message)+1)
+	binary.BigEndian.PutUint16(packet[0:], opERROR)
+	binary.BigEndian.PutUint16(packet[2:], uint16(code))
+	packet = append(packet, message...)
+	return append(packet, 0)
+}
+
+// parseAck parses an ACK packet and returns its block number. An ERROR
+// packet of the client is returned as error to abort the transfer.
+func parseAck(packet []byte) (uint16, error) {
+	if len(packet) < 4 {
+		return 0, errors.New("packet too short")
+	}
+
+	switch binary.BigEndian.Uint16(packet) {
+	case opACK:
+		return binary.BigEndian.Uint16(packet[2:]), nil
+	case opERROR:
+		message := strings.TrimRight(string(packet[4:]), "\x00")
+		return 0, fmt.Errorf("client aborted transfer with code %d: %s", binary.BigEndian.Uint16(packet[2:]), message)
+	default:
+		return 0, fmt.Errorf("unexpected opcode %d", binary.BigEndian.Uint16(packet))
+	}
+}
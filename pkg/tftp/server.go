@@ -0,0 +1,303 @@
+package tftp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Limits of the block size as defined in RFC 2348.
+const (
+	DefaultBlockSize = 512
+	MinBlockSize     = 8
+	MaxBlockSize     = 65464
+)
+
+// DefaultTimeout is the time to wait for an acknowledgement
+// before a packet is retransmitted, unless the client
+// negotiates a different timeout as defined in RFC 2349.
+const DefaultTimeout = 3 * time.Second
+
+// DefaultRetries is the number of retransmissions before a transfer is aborted.
+const DefaultRetries = 5
+
+// Handler returns the content of the files that are requested by clients.
+type Handler interface {
+	// ReadFile returns the content of a file. Errors that
+	// wrap fs.ErrNotExist are reported as "File not found".
+	ReadFile(name string) ([]byte, error)
+}
+
+// HandlerFunc allows to use a function as Handler.
+type HandlerFunc func(name string) ([]byte, error)
+
+// ReadFile calls the function.
+func (f HandlerFunc) ReadFile(name string) ([]byte, error) {
+	return f(name)
+}
+
+// Server is a read-only TFTP server as defined in RFC 1350 that supports
+// the blksize, timeout and tsize options of RFC 2348 and RFC 2349.
+type Server struct {
+	handler Handler
+	logger  *slog.Logger
+
+	// Timeout is the time to wait for an acknowledgement
+	// before a packet is retransmitted.
+	Timeout time.Duration
+	// Retries is the number of retransmissions before a transfer is aborted.
+	Retries int
+}
+
+// NewServer creates a TFTP server that serves the files of the handler.
+func NewServer(handler Handler, logger *slog.Logger) *Server {
+	return &Server{
+		handler: handler,
+		logger:  logger,
+		Timeout: DefaultTimeout,
+		Retries: DefaultRetries,
+	}
+}
+
+// ListenAndServe listens on the UDP address and serves
+// requests until the context is cancelled.
+func (s *Server) ListenAndServe(ctx context.Context, address string) error {
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	return s.Serve(ctx, conn)
+}
+
+// Serve serves requests that are received on the connection until the
+// context is cancelled. Every transfer uses its own ephemeral port.
+func (s *Server) Serve(ctx context.Context, conn net.PacketConn) error {
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	host := ""
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok && !addr.IP.IsUnspecified() {
+		host = addr.IP.String()
+	}
+
+	buffer := make([]byte, 65536)
+	for {
+		n, peer, err := conn.ReadFrom(buffer)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("failed to read request: %w", err)
+		}
+
+		go s.handle(host, peer, bytes.Clone(buffer[:n]))
+	}
+}
+
+// handle answers a single request from a new transfer ID.
+func (s *Server) handle(host string, peer net.Addr, packet []byte) {
+	logger := s.logger.With("client", peer.String())
+
+	conn, err := net.ListenPacket("udp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		logger.Error("Failed to create transfer", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	if len(packet) < 2 {
+		return
+	}
+
+	switch binary.BigEndian.Uint16(packet) {
+	case opRRQ:
+	case opWRQ:
+		conn.WriteTo(errorPacket(ErrorAccessViolation, "server is read-only"), peer)
+		return
+	default:
+		conn.WriteTo(errorPacket(ErrorIllegalOperation, "expected read request"), peer)
+		return
+	}
+
+	request, err := parseRequest(packet[2:])
+	if err != nil {
+		conn.WriteTo(errorPacket(ErrorIllegalOperation, err.Error()), peer)
+		return
+	}
+
+	logger = logger.With("file", request.filename)
+
+	if request.mode != "octet" && request.mode != "netascii" {
+		conn.WriteTo(errorPacket(ErrorIllegalOperation, "unsupported mode: "+request.mode), peer)
+		return
+	}
+
+	data, err := s.handler.ReadFile(request.filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			logger.Info("File not found")
+			conn.WriteTo(errorPacket(ErrorFileNotFound, "file not found"), peer)
+			return
+		}
+
+		logger.Error("Failed to read file", "error", err)
+		conn.WriteTo(errorPacket(ErrorNotDefined, "failed to read file"), peer)
+		return
+	}
+
+	if request.mode == "netascii" {
+		data = toNetASCII(data)
+	}
+
+	transfer := &transfer{
+		conn:      conn,
+		peer:      peer,
+		blockSize: DefaultBlockSize,
+		timeout:   s.Timeout,
+		retries:   s.Retries,
+	}
+
+	if err := transfer.negotiate(request.options, len(data)); err != nil {
+		logger.Warn("Failed to negotiate options", "error", err)
+		return
+	}
+
+	if err := transfer.send(data); err != nil {
+		logger.Warn("Failed to send file", "error", err)
+		return
+	}
+
+	logger.Info("Sent file", "size", len(data), "blksize", transfer.blockSize)
+}
+
+// transfer sends a file to a client.
+type transfer struct {
+	conn      net.PacketConn
+	peer      net.Addr
+	blockSize int
+	timeout   time.Duration
+	retries   int
+}
+
+// negotiate applies the supported options of the client and acknowledges
+// them with an OACK packet. Unsupported and invalid options are ignored.
+func (t *transfer) negotiate(options map[string]string, size int) error {
+	var accepted [][2]string
+
+	if value, ok := options["blksize"]; ok {
+		if blockSize, err := strconv.Atoi(value); err == nil && blockSize >= MinBlockSize {
+			t.blockSize = min(blockSize, MaxBlockSize)
+			accepted = append(accepted, [2]string{"blksize", strconv.Itoa(t.blockSize)})
+		}
+	}
+
+	if value, ok := options["timeout"]; ok {
+		if timeout, err := strconv.Atoi(value); err == nil && timeout >= 1 && timeout <= 255 {
+			t.timeout = time.Duration(timeout) * time.Second
+			accepted = append(accepted, [2]string{"timeout", value})
+		}
+	}
+
+	if _, ok := options["tsize"]; ok {
+		accepted = append(accepted, [2]string{"tsize", strconv.Itoa(size)})
+	}
+
+	if len(accepted) == 0 {
+		return nil
+	}
+
+	return t.exchange(oackPacket(accepted), 0)
+}
+
+// send sends the data in blocks. The transfer ends
+// with a block that is smaller than the block size.
+func (t *transfer) send(data []byte) error {
+	block := uint16(1)
+	for offset := 0; ; offset += t.blockSize {
+		end := min(offset+t.blockSize, len(data))
+		if err := t.exchange(dataPacket(block, data[offset:end]), block); err != nil {
+			return err
+		}
+
+		if end-offset < t.blockSize {
+			return nil
+		}
+
+		// Block numbers wrap around for files with more than 65535 blocks.
+		block++
+	}
+}
+
+// exchange sends a packet until the client acknowledges the block.
+// Duplicate acknowledgements of previous blocks are ignored instead of
+// triggering a retransmission to avoid the Sorcerer's Apprentice Syndrome.
+func (t *transfer) exchange(packet []byte, block uint16) error {
+	buffer := make([]byte, 512)
+
+	for attempt := 0; attempt <= t.retries; attempt++ {
+		if _, err := t.conn.WriteTo(packet, t.peer); err != nil {
+			return fmt.Errorf("failed to send block %d: %w", block, err)
+		}
+
+		if err := t.conn.SetReadDeadline(time.Now().Add(t.timeout)); err != nil {
+			return fmt.Errorf("failed to set deadline: %w", err)
+		}
+
+		for {
+			n, addr, err := t.conn.ReadFrom(buffer)
+			if err != nil {
+				if errors.Is(err, os.ErrDeadlineExceeded) {
+					break
+				}
+
+				return fmt.Errorf("failed to receive acknowledgement: %w", err)
+			}
+
+			if addr.String() != t.peer.String() {
+				t.conn.WriteTo(errorPacket(ErrorUnknownTransferID, "unknown transfer ID"), addr)
+				continue
+			}
+
+			acknowledged, err := parseAck(buffer[:n])
+			if err != nil {
+				return err
+			}
+
+			if acknowledged == block {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("timed out waiting for acknowledgement of block %d", block)
+}
+
+// toNetASCII converts line endings to CR LF and
+// escapes bare carriage returns as CR NUL.
+func toNetASCII(data []byte) []byte {
+	converted := make([]byte, 0, len(data))
+	for _, b := range data {
+		switch b {
+		case '\n':
+			converted = append(converted, '\r', '\n')
+		case '\r':
+			converted = append(converted, '\r', 0)
+		default:
+			converted = append(converted, b)
+		}
+	}
+
+	return converted
+}
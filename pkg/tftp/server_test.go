@@ -0,0 +1,154 @@
+package tftp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// response is the result of a download by the test client.
+type response struct {
+	data    []byte
+	options map[string]string
+	code    ErrorCode
+	failed  bool
+}
+
+// download requests a file from the server and acknowledges every block.
+func download(server net.Addr, opcode uint16, filename string, mode string, options ...string) response {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	defer conn.Close()
+
+	request := binary.BigEndian.AppendUint16(nil, opcode)
+	for _, field := range append([]string{filename, mode}, options...) {
+		request = append(request, field...)
+		request = append(request, 0)
+	}
+	_, err = conn.WriteTo(request, server)
+	Expect(err).NotTo(HaveOccurred())
+
+	result := response{}
+	buffer := make([]byte, 65536)
+	blockSize := DefaultBlockSize
+	for {
+		Expect(conn.SetReadDeadline(time.Now().Add(5 * time.Second))).To(Succeed())
+		n, peer, err := conn.ReadFrom(buffer)
+		Expect(err).NotTo(HaveOccurred())
+
+		packet := buffer[:n]
+		switch binary.BigEndian.Uint16(packet) {
+		case opERROR:
+			result.code = ErrorCode(binary.BigEndian.Uint16(packet[2:]))
+			result.failed = true
+			return result
+		case opOACK:
+			request, err := parseRequest(append([]byte("file\x00octet\x00"), packet[2:]...))
+			Expect(err).NotTo(HaveOccurred())
+			result.options = request.options
+			if value, ok := result.options["blksize"]; ok {
+				blockSize, err = strconv.Atoi(value)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			_, err = conn.WriteTo([]byte{0, byte(opACK), 0, 0}, peer)
+			Expect(err).NotTo(HaveOccurred())
+		case opDATA:
+			result.data = append(result.data, packet[4:]...)
+			_, err = conn.WriteTo([]byte{0, byte(opACK), packet[2], packet[3]}, peer)
+			Expect(err).NotTo(HaveOccurred())
+			if len(packet)-4 < blockSize {
+				return result
+			}
+		default:
+			Fail("unexpected packet")
+		}
+	}
+}
+
+var _ = Describe("Server", func() {
+	var (
+		cancel  context.CancelFunc
+		address net.Addr
+	)
+
+	files := map[string][]byte{
+		"small.txt":     []byte("line 1\nline 2\r\n"),
+		"undionly.kpxe": bytes.Repeat([]byte{0xeb, 0x3c, 0x90}, 700),
+		"empty":         {},
+		"aligned":       bytes.Repeat([]byte{1}, 1024),
+	}
+
+	BeforeEach(func() {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		address = conn.LocalAddr()
+
+		server := NewServer(HandlerFunc(func(name string) ([]byte, error) {
+			if data, ok := files[name]; ok {
+				return data, nil
+			}
+			return nil, fs.ErrNotExist
+		}), slog.New(slog.NewTextHandler(io.Discard, nil)))
+		server.Timeout = 200 * time.Millisecond
+
+		go server.Serve(ctx, conn)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should send a file in blocks of 512 bytes", func() {
+		result := download(address, opRRQ, "undionly.kpxe", "octet")
+		Expect(result.failed).To(BeFalse())
+		Expect(result.options).To(BeNil())
+		Expect(result.data).To(Equal(files["undionly.kpxe"]))
+	})
+
+	It("should terminate files that are a multiple of the block size with an empty block", func() {
+		Expect(download(address, opRRQ, "aligned", "octet").data).To(Equal(files["aligned"]))
+		Expect(download(address, opRRQ, "empty", "octet").data).To(BeEmpty())
+	})
+
+	It("should negotiate the block size and the transfer size", func() {
+		result := download(address, opRRQ, "undionly.kpxe", "octet", "blksize", "1468", "tsize", "0", "unknown", "1")
+		Expect(result.failed).To(BeFalse())
+		Expect(result.options).To(Equal(map[string]string{"blksize": "1468", "tsize": "2100"}))
+		Expect(result.data).To(Equal(files["undionly.kpxe"]))
+	})
+
+	It("should convert line endings in netascii mode", func() {
+		result := download(address, opRRQ, "small.txt", "netascii")
+		Expect(string(result.data)).To(Equal("line 1\r\nline 2\r\x00\r\n"))
+	})
+
+	It("should report missing files", func() {
+		result := download(address, opRRQ, "missing", "octet")
+		Expect(result.failed).To(BeTrue())
+		Expect(result.code).To(Equal(ErrorFileNotFound))
+	})
+
+	It("should reject write requests", func() {
+		result := download(address, opWRQ, "small.txt", "octet")
+		Expect(result.failed).To(BeTrue())
+		Expect(result.code).To(Equal(ErrorAccessViolation))
+	})
+
+	It("should reject unsupported modes", func() {
+		result := download(address, opRRQ, "small.txt", "mail")
+		Expect(result.failed).To(BeTrue())
+		Expect(result.code).To(Equal(ErrorIllegalOperation))
+	})
+})
@@ -0,0 +1,13 @@
+package tftp
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTFTP(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "tftp suite")
+}
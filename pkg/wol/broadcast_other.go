@@ -0,0 +1,11 @@
+//go:build !unix
+
+package wol
+
+import "net"
+
+// setBroadcast is a no-op, because sockets may send to
+// broadcast addresses by default on other platforms.
+func setBroadcast(conn *net.UDPConn) error {
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build unix
+
+package wol
+
+import (
+	"net"
+	"syscall"
+)
+
+// setBroadcast allows the socket to send to broadcast addresses.
+func setBroadcast(conn *net.UDPConn) error {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+	if err := raw.Control(func(fd uintptr) {
+		sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_BROADCAST, 1)
+	}); err != nil {
+		return err
+	}
+
+	return sockErr
+}
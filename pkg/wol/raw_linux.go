@@ -0,0 +1,33 @@
+//go:build linux
+
+package wol
+
+import (
+	"net"
+	"syscall"
+)
+
+// sendFrame sends the Ethernet frame via a packet socket on the interface.
+func sendFrame(iface *net.Interface, frame []byte) error {
+	protocol := htons(EtherType)
+
+	fd, err := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_RAW, int(protocol))
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(fd)
+
+	address := &syscall.SockaddrLinklayer{
+		Protocol: protocol,
+		Ifindex:  iface.Index,
+		Halen:    6,
+	}
+	copy(address.Addr[:], Broadcast)
+
+	return syscall.Sendto(fd, frame, 0, address)
+}
+
+// htons converts a short from host to network byte order.
+func htons(value uint16) uint16 {
+	return value<<8 | value>>8
+}
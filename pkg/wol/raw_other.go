@@ -0,0 +1,13 @@
+//go:build !linux
+
+package wol
+
+import (
+	"errors"
+	"net"
+)
+
+// sendFrame is not supported, because packet sockets are specific to Linux.
+func sendFrame(iface *net.Interface, frame []byte) error {
+	return errors.ErrUnsupported
+}
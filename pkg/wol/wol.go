@@ -0,0 +1,113 @@
+package wol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// DefaultAddress is the limited broadcast address that magic packets are sent to via UDP.
+const DefaultAddress = "255.255.255.255:9"
+
+// EtherType is the EtherType of magic packets that are sent as raw Ethernet frames.
+const EtherType = 0x0842
+
+// Broadcast is the broadcast address of Ethernet frames.
+var Broadcast = net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+// ParsePassword parses a SecureOn password, which consists of either 4 bytes
+// in the format of an IPv4 address, e.g. "192.168.1.1", or of 6 bytes in the
+// format of a MAC address, e.g. "00:11:22:33:44:55". An empty password is
+// returned for an empty string.
+func ParsePassword(password string) ([]byte, error) {
+	if password == "" {
+		return nil, nil
+	}
+
+	if ip := net.ParseIP(password); ip != nil && ip.To4() != nil {
+		return ip.To4(), nil
+	}
+
+	if hw, err := net.ParseMAC(password); err == nil && len(hw) == 6 {
+		return hw, nil
+	}
+
+	return nil, fmt.Errorf("invalid SecureOn password: must be 4 or 6 bytes, e.g. 192.168.1.1 or 00:11:22:33:44:55")
+}
+
+// MagicPacket returns the magic packet that wakes the interface with the
+// MAC address. It consists of 6 bytes of 0xff followed by 16 repetitions
+// of the MAC address and the optional SecureOn password.
+func MagicPacket(mac net.HardwareAddr, password []byte) ([]byte, error) {
+	if len(mac) != 6 {
+		return nil, fmt.Errorf("invalid MAC address: must be 6 bytes: %s", mac)
+	}
+
+	if len(password) != 0 && len(password) != 4 && len(password) != 6 {
+		return nil, fmt.Errorf("invalid SecureOn password: must be 4 or 6 bytes")
+	}
+
+	packet := make([]byte, 0, 6+16*6+len(password))
+	packet = append(packet, Broadcast...)
+	packet = append(packet, bytes.Repeat(mac, 16)...)
+	packet = append(packet, password...)
+
+	return packet, nil
+}
+
+// EthernetFrame returns the Ethernet frame that broadcasts the magic
+// packet from the interface with the source MAC address.
+func EthernetFrame(source net.HardwareAddr, packet []byte) []byte {
+	frame := make([]byte, 0, 14+len(packet))
+	frame = append(frame, Broadcast...)
+	frame = append(frame, source...)
+	frame = binary.BigEndian.AppendUint16(frame, EtherType)
+
+	return append(frame, packet...)
+}
+
+// SendUDP sends the magic packet to the address via UDP, e.g. to the
+// limited broadcast address or to the broadcast address of a network.
+func SendUDP(address string, packet []byte) error {
+	raddr, err := net.ResolveUDPAddr("udp4", address)
+	if err != nil {
+		return fmt.Errorf("failed to resolve address: %w", err)
+	}
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return fmt.Errorf("failed to open socket: %w", err)
+	}
+	defer conn.Close()
+
+	if err := setBroadcast(conn); err != nil {
+		return fmt.Errorf("failed to enable broadcast: %w", err)
+	}
+
+	if _, err := conn.WriteToUDP(packet, raddr); err != nil {
+		return fmt.Errorf("failed to send magic packet: %w", err)
+	}
+
+	return nil
+}
+
+// SendRaw broadcasts the magic packet as raw Ethernet frame on the network
+// interface, which also wakes Machines on networks without IP configuration.
+// It requires the capability CAP_NET_RAW and is only supported on Linux.
+func SendRaw(name string, packet []byte) error {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return fmt.Errorf("failed to look up interface: %w", err)
+	}
+
+	if len(iface.HardwareAddr) != 6 {
+		return fmt.Errorf("interface %s has no Ethernet address", name)
+	}
+
+	if err := sendFrame(iface, EthernetFrame(iface.HardwareAddr, packet)); err != nil {
+		return fmt.Errorf("failed to send magic packet: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package wol
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestWOL(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "wol suite")
+}
@@ -0,0 +1,90 @@
+package wol
+
+import (
+	"bytes"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MagicPacket", func() {
+	mac := net.HardwareAddr{0x32, 0xde, 0xfa, 0x97, 0x71, 0x4f}
+
+	It("should repeat the MAC address 16 times after the synchronization stream", func() {
+		packet, err := MagicPacket(mac, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(packet).To(HaveLen(102))
+		Expect(packet[:6]).To(Equal([]byte(Broadcast)))
+		Expect(packet[6:]).To(Equal(bytes.Repeat(mac, 16)))
+	})
+
+	It("should append the SecureOn password", func() {
+		password, err := ParsePassword("00:11:22:33:44:55")
+		Expect(err).NotTo(HaveOccurred())
+
+		packet, err := MagicPacket(mac, password)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(packet).To(HaveLen(108))
+		Expect(packet[102:]).To(Equal([]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}))
+	})
+
+	It("should reject invalid MAC addresses", func() {
+		_, err := MagicPacket(net.HardwareAddr{0x32, 0xde}, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ParsePassword", func() {
+	It("should parse passwords in the format of IPv4 addresses", func() {
+		Expect(ParsePassword("192.168.1.1")).To(Equal([]byte{192, 168, 1, 1}))
+	})
+
+	It("should parse passwords in the format of MAC addresses", func() {
+		Expect(ParsePassword("00-11-22-33-44-55")).To(Equal([]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}))
+	})
+
+	It("should return no password for an empty string", func() {
+		Expect(ParsePassword("")).To(BeEmpty())
+	})
+
+	It("should reject passwords of other lengths", func() {
+		_, err := ParsePassword("secret")
+		Expect(err).To(HaveOccurred())
+
+		_, err = ParsePassword("fd00::1")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("EthernetFrame", func() {
+	It("should broadcast the packet with the EtherType of Wake-on-LAN", func() {
+		source := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+		frame := EthernetFrame(source, []byte{1, 2, 3})
+		Expect(frame).To(Equal([]byte{
+			0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+			0x02, 0x00, 0x00, 0x00, 0x00, 0x01,
+			0x08, 0x42,
+			1, 2, 3,
+		}))
+	})
+})
+
+var _ = Describe("SendUDP", func() {
+	It("should send the packet to the address", func() {
+		conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		packet, err := MagicPacket(net.HardwareAddr{0x32, 0xde, 0xfa, 0x97, 0x71, 0x4f}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(SendUDP(conn.LocalAddr().String(), packet)).To(Succeed())
+
+		buffer := make([]byte, 1500)
+		Expect(conn.SetReadDeadline(time.Now().Add(5 * time.Second))).To(Succeed())
+		n, _, err := conn.ReadFrom(buffer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer[:n]).To(Equal(packet))
+	})
+})